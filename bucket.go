@@ -0,0 +1,74 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// LstBucket lists buckets in name order.
+type LstBucket func(ctx context.Context, cb func(bucket string) error) error
+
+type HasBucket func(ctx context.Context, bucket string) (bool, error)
+
+// BucketQueryGenerator creates the queries which read the buckets.
+//
+// Queries may read a table which records the buckets(e.g. a registry created on demand);
+// ErrBucketNotFound from such a table means no buckets.
+type BucketQueryGenerator interface {
+	// LstBucket: returns bucket names
+	LstBucket() (query string, e error)
+	// HasBucket: returns true if the bucket exists
+	HasBucket(bucket string) (query string, e error)
+}
+
+func lstBucketNew(g BucketQueryGenerator, q QueryCb) LstBucket {
+	return func(ctx context.Context, cb func(bucket string) error) error {
+		query, e := g.LstBucket()
+		if nil != e {
+			return e
+		}
+		var read bool = false
+		e = q(
+			ctx,
+			func(r Record) error {
+				read = true
+				var bucket string
+				e := r.Scan(&bucket)
+				if nil != e {
+					return fmt.Errorf("Unable to get bucket name: %w", e)
+				}
+				return cb(bucket)
+			},
+			query,
+		)
+		if !read && errors.Is(e, ErrBucketNotFound) {
+			return nil
+		}
+		return e
+	}
+}
+
+func hasBucketNew(g BucketQueryGenerator, q Query) HasBucket {
+	return func(ctx context.Context, bucket string) (found bool, e error) {
+		query, e := g.HasBucket(bucket)
+		if nil != e {
+			return false, e
+		}
+		e = q(ctx, query).Scan(&found)
+		if errors.Is(e, ErrBucketNotFound) {
+			return false, nil
+		}
+		return
+	}
+}
+
+var LstBucketFactory func(driverName string) func(QueryCb) LstBucket = compose(
+	getQueryGeneratorExtOrEmpty[BucketQueryGenerator],
+	curry(lstBucketNew),
+)
+
+var HasBucketFactory func(driverName string) func(Query) HasBucket = compose(
+	getQueryGeneratorExtOrEmpty[BucketQueryGenerator],
+	curry(hasBucketNew),
+)
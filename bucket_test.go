@@ -0,0 +1,96 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type dummyBucketRecord struct{ bucket string }
+
+func (d dummyBucketRecord) Scan(dest ...any) error {
+	*(dest[0].(*string)) = d.bucket
+	return nil
+}
+
+func TestLstBucketFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("bucket-dummy", &emptyQueryGenerator{})
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var l LstBucket = LstBucketFactory("does-not-exist")(nil)
+		e := l(context.Background(), func(_ string) error { return nil })
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("names", func(t *testing.T) {
+		t.Parallel()
+		var q QueryCb = func(_ context.Context, cb RecordConsumer, _ string, _ ...any) error {
+			for _, b := range []string{"b0", "b1"} {
+				e := cb(dummyBucketRecord{b})
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+		var l LstBucket = LstBucketFactory("bucket-dummy")(q)
+		var got []string
+		e := l(context.Background(), func(bucket string) error {
+			got = append(got, bucket)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 2 != len(got) || "b1" != got[1] {
+			t.Errorf("Unexpected buckets: %v", got)
+		}
+	})
+
+	t.Run("missing registry", func(t *testing.T) {
+		t.Parallel()
+		var q QueryCb = func(_ context.Context, _ RecordConsumer, _ string, _ ...any) error {
+			return ErrBucketNotFound
+		}
+		var l LstBucket = LstBucketFactory("bucket-dummy")(q)
+		e := l(context.Background(), func(_ string) error { return nil })
+		if nil != e {
+			t.Errorf("Must be empty: %v", e)
+		}
+	})
+}
+
+func TestHasBucketFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("bucket-dummy", &emptyQueryGenerator{})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrInvalidBucket}
+		}
+		var h HasBucket = HasBucketFactory("bucket-dummy")(q)
+		_, e := h(context.Background(), "b")
+		if !errors.Is(e, ErrInvalidBucket) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+
+	t.Run("missing registry", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrBucketNotFound}
+		}
+		var h HasBucket = HasBucketFactory("bucket-dummy")(q)
+		found, e := h(context.Background(), "b")
+		if nil != e || found {
+			t.Errorf("Must be false: %v, %v", found, e)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+// RenameBucket renames the bucket src to dst(dst must not exist).
+type RenameBucket func(ctx context.Context, src, dst string) error
+
+// CopyBucket creates the new bucket dst which has the same layout and rows as src.
+type CopyBucket func(ctx context.Context, src, dst string) error
+
+// TruncateBucket removes all rows from the bucket.
+type TruncateBucket func(ctx context.Context, bucket string) error
+
+type BucketOpQueryGenerator interface {
+	RenameBucket(src, dst string) (query string, e error)
+	CopyBucket(src, dst string) (query string, e error)
+	TruncateBucket(bucket string) (query string, e error)
+}
+
+func renameBucketNew(g BucketOpQueryGenerator, x Exec) RenameBucket {
+	return func(ctx context.Context, src, dst string) error {
+		query, e := g.RenameBucket(src, dst)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+func copyBucketNew(g BucketOpQueryGenerator, x Exec) CopyBucket {
+	return func(ctx context.Context, src, dst string) error {
+		query, e := g.CopyBucket(src, dst)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+func truncateBucketNew(g BucketOpQueryGenerator, x Exec) TruncateBucket {
+	return func(ctx context.Context, bucket string) error {
+		query, e := g.TruncateBucket(bucket)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+var RenameBucketFactory func(driverName string) func(Exec) RenameBucket = compose(
+	getQueryGeneratorExtOrEmpty[BucketOpQueryGenerator],
+	curry(renameBucketNew),
+)
+
+var CopyBucketFactory func(driverName string) func(Exec) CopyBucket = compose(
+	getQueryGeneratorExtOrEmpty[BucketOpQueryGenerator],
+	curry(copyBucketNew),
+)
+
+var TruncateBucketFactory func(driverName string) func(Exec) TruncateBucket = compose(
+	getQueryGeneratorExtOrEmpty[BucketOpQueryGenerator],
+	curry(truncateBucketNew),
+)
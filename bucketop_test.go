@@ -0,0 +1,46 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBucketOpFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("bucketop-dummy", &emptyQueryGenerator{})
+
+	var x Exec = func(_ context.Context, _ string, _ ...any) error { return nil }
+
+	t.Run("RenameBucket", func(t *testing.T) {
+		t.Parallel()
+		var r RenameBucket = RenameBucketFactory("does-not-exist")(x)
+		e := r(context.Background(), "src", "dst")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+		r = RenameBucketFactory("bucketop-dummy")(x)
+		e = r(context.Background(), "src", "dst")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+
+	t.Run("CopyBucket", func(t *testing.T) {
+		t.Parallel()
+		var c CopyBucket = CopyBucketFactory("does-not-exist")(x)
+		e := c(context.Background(), "src", "dst")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("TruncateBucket", func(t *testing.T) {
+		t.Parallel()
+		var tr TruncateBucket = TruncateBucketFactory("does-not-exist")(x)
+		e := tr(context.Background(), "b")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+// CompareAndSet sets val only if the current value is expected(nil: key must not exist).
+// Returns ErrConflict if the precondition fails.
+type CompareAndSet func(ctx context.Context, bucket string, key, expected, val []byte) error
+
+// GetVersion gets the value and its version from a versioned bucket.
+type GetVersion func(ctx context.Context, bucket string, key []byte) (val []byte, version int64, e error)
+
+// CompareAndSetVersion sets val only if the current version is version(0: key must not exist).
+// Returns the new version or ErrConflict if the precondition fails.
+//
+// Only CompareAndSetVersion changes versions: Set/Add on a versioned bucket keep the version(Add: 1),
+// so writes by Set are not detected; write versioned buckets only by CompareAndSetVersion.
+type CompareAndSetVersion func(ctx context.Context, bucket string, key []byte, version int64, val []byte) (newVersion int64, e error)
+
+type CasQueryGenerator interface {
+	// CasUpdate: $1 key, $2 expected, $3 val; returns key if updated
+	CasUpdate(bucket string) (query string, e error)
+	// CasInsert: $1 key, $2 val; returns key if inserted
+	CasInsert(bucket string) (query string, e error)
+
+	// GetVersion: $1 key; returns val, version
+	GetVersion(bucket string) (query string, e error)
+	// CasVersionUpdate: $1 key, $2 version, $3 val; returns new version if updated
+	CasVersionUpdate(bucket string) (query string, e error)
+	// CasVersionInsert: $1 key, $2 val; returns new version if inserted
+	CasVersionInsert(bucket string) (query string, e error)
+	AddVersionBucket(bucket string) (query string, e error)
+}
+
+// notFound2conflict converts ErrNotFound(no row updated) into ErrConflict.
+func notFound2conflict(e error) error { return ErrorReplaceNew(ErrNotFound, ErrConflict, e) }
+
+func compareAndSetNew(g CasQueryGenerator, q Query) CompareAndSet {
+	return func(ctx context.Context, bucket string, key, expected, val []byte) error {
+		var query string
+		var e error
+		var args []any
+		if nil == expected {
+			query, e = g.CasInsert(bucket)
+			args = []any{key, val}
+		} else {
+			query, e = g.CasUpdate(bucket)
+			args = []any{key, expected, val}
+		}
+		if nil != e {
+			return e
+		}
+		_, e = record2val(q(ctx, query, args...))
+		return notFound2conflict(e)
+	}
+}
+
+func getVersionNew(g CasQueryGenerator, q Query) GetVersion {
+	return func(ctx context.Context, bucket string, key []byte) (val []byte, version int64, e error) {
+		query, e := g.GetVersion(bucket)
+		if nil != e {
+			return nil, 0, e
+		}
+		e = q(ctx, query, key).Scan(&val, &version)
+		return
+	}
+}
+
+func compareAndSetVersionNew(g CasQueryGenerator, q Query) CompareAndSetVersion {
+	return func(ctx context.Context, bucket string, key []byte, version int64, val []byte) (newVersion int64, e error) {
+		var query string
+		var args []any
+		if 0 == version {
+			query, e = g.CasVersionInsert(bucket)
+			args = []any{key, val}
+		} else {
+			query, e = g.CasVersionUpdate(bucket)
+			args = []any{key, version, val}
+		}
+		if nil != e {
+			return 0, e
+		}
+		e = q(ctx, query, args...).Scan(&newVersion)
+		return newVersion, notFound2conflict(e)
+	}
+}
+
+func addVersionBucketNew(g CasQueryGenerator, x Exec) AddBucket {
+	return func(ctx context.Context, bucket string) error {
+		query, e := g.AddVersionBucket(bucket)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+var CompareAndSetFactory func(driverName string) func(Query) CompareAndSet = compose(
+	getQueryGeneratorExtOrEmpty[CasQueryGenerator],
+	curry(compareAndSetNew),
+)
+
+var GetVersionFactory func(driverName string) func(Query) GetVersion = compose(
+	getQueryGeneratorExtOrEmpty[CasQueryGenerator],
+	curry(getVersionNew),
+)
+
+var CompareAndSetVersionFactory func(driverName string) func(Query) CompareAndSetVersion = compose(
+	getQueryGeneratorExtOrEmpty[CasQueryGenerator],
+	curry(compareAndSetVersionNew),
+)
+
+var AddVersionBucketFactory func(driverName string) func(Exec) AddBucket = compose(
+	getQueryGeneratorExtOrEmpty[CasQueryGenerator],
+	curry(addVersionBucketNew),
+)
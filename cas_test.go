@@ -0,0 +1,125 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type dummyRecord struct{ e error }
+
+func (d dummyRecord) Scan(_ ...any) error { return d.e }
+
+func TestCompareAndSetFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("cas-dummy", &emptyQueryGenerator{})
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var cas CompareAndSet = CompareAndSetFactory("does-not-exist")(nil)
+		e := cas(context.Background(), "b", nil, nil, nil)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrNotFound}
+		}
+		var cas CompareAndSet = CompareAndSetFactory("cas-dummy")(q)
+		e := cas(context.Background(), "b", []byte("k"), []byte("v"), []byte("w"))
+		if !errors.Is(e, ErrConflict) {
+			t.Errorf("Must be ErrConflict: %v", e)
+		}
+		if errors.Is(e, ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("conflict keeps native error", func(t *testing.T) {
+		t.Parallel()
+		native := errors.New("no rows")
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrorConvertNew(ErrNotFound, native)}
+		}
+		var cas CompareAndSet = CompareAndSetFactory("cas-dummy")(q)
+		e := cas(context.Background(), "b", []byte("k"), []byte("v"), []byte("w"))
+		if !errors.Is(e, ErrConflict) || !errors.Is(e, native) {
+			t.Errorf("Must be ErrConflict(native): %v", e)
+		}
+		if errors.Is(e, ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("insert args", func(t *testing.T) {
+		t.Parallel()
+		var argc int
+		var q Query = func(_ context.Context, _ string, args ...any) Record {
+			argc = len(args)
+			return dummyRecord{nil}
+		}
+		var cas CompareAndSet = CompareAndSetFactory("cas-dummy")(q)
+		e := cas(context.Background(), "b", []byte("k"), nil, []byte("w"))
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 2 != argc {
+			t.Errorf("Unexpected number of args: %v", argc)
+		}
+	})
+}
+
+func TestCompareAndSetVersionFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("cas-dummy", &emptyQueryGenerator{})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrNotFound}
+		}
+		var cas CompareAndSetVersion = CompareAndSetVersionFactory("cas-dummy")(q)
+		_, e := cas(context.Background(), "b", []byte("k"), 1, []byte("w"))
+		if !errors.Is(e, ErrConflict) {
+			t.Errorf("Must be ErrConflict: %v", e)
+		}
+		if errors.Is(e, ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrBucketNotFound}
+		}
+		var cas CompareAndSetVersion = CompareAndSetVersionFactory("cas-dummy")(q)
+		_, e := cas(context.Background(), "b", []byte("k"), 0, []byte("w"))
+		if errors.Is(e, ErrConflict) {
+			t.Errorf("Must not be ErrConflict: %v", e)
+		}
+	})
+
+	t.Run("get version", func(t *testing.T) {
+		t.Parallel()
+		var gv GetVersion = GetVersionFactory("does-not-exist")(nil)
+		_, _, e := gv(context.Background(), "b", nil)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("add bucket", func(t *testing.T) {
+		t.Parallel()
+		var ab AddBucket = AddVersionBucketFactory("does-not-exist")(nil)
+		e := ab(context.Background(), "b")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package sql2keyval
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+var (
+	ErrNotFound       = errors.New("Not found")
+	ErrBucketNotFound = errors.New("Bucket not found")
+	ErrInvalidBucket  = errors.New("Invalid bucket")
+	ErrDuplicateKey   = errors.New("Duplicate key")
+	ErrConflict       = errors.New("Conflict")
+	ErrInvalidValue   = errors.New("Invalid value")
+)
+
+// convertedError keeps the native error while matching a sentinel error by errors.Is.
+type convertedError struct {
+	sentinel error
+	native   error
+}
+
+func (c convertedError) Error() string        { return c.sentinel.Error() + ": " + c.native.Error() }
+func (c convertedError) Unwrap() error        { return c.native }
+func (c convertedError) Is(target error) bool { return target == c.sentinel }
+
+// ErrorConvertNew creates an error which satisfies both errors.Is(e, sentinel) and errors.Is(e, native).
+func ErrorConvertNew(sentinel error, native error) error {
+	if errors.Is(native, sentinel) {
+		return native // already converted
+	}
+	return convertedError{
+		sentinel,
+		native,
+	}
+}
+
+// ErrorReplaceNew replaces the sentinel error from of e with to(keeps the native error only).
+func ErrorReplaceNew(from, to error, e error) error {
+	var c convertedError
+	if errors.As(e, &c) && from == c.sentinel {
+		return ErrorConvertNew(to, c.native)
+	}
+	if errors.Is(e, from) {
+		return to
+	}
+	return e
+}
+
+type sqlStateError interface{ SQLState() string }
+
+var sqlState2sentinel = map[string]error{
+	"42P01": ErrBucketNotFound, // postgres: undefined_table
+	"23505": ErrDuplicateKey,   // postgres: unique_violation
+}
+
+// ErrorConverter gets the sentinel error of a driver specific error(nil if unknown).
+type ErrorConverter func(e error) (sentinel error)
+
+var errorConverters []ErrorConverter
+var errorConvertersL sync.RWMutex
+
+// RegisterErrorConverter adds a converter for drivers without SQLSTATE(e.g. mysql, sqlite).
+func RegisterErrorConverter(c ErrorConverter) {
+	errorConvertersL.Lock()
+	defer errorConvertersL.Unlock()
+	if nil == c {
+		return // ignore invalid converter
+	}
+	errorConverters = append(errorConverters, c)
+}
+
+func errorFromConverters(e error) error {
+	errorConvertersL.RLock()
+	defer errorConvertersL.RUnlock()
+	for _, c := range errorConverters {
+		sentinel := c(e)
+		if nil != sentinel {
+			return ErrorConvertNew(sentinel, e)
+		}
+	}
+	return e
+}
+
+// ErrorCode gets the integer field of the wrapped error whose type is defined in the package pkg(import path).
+// Drivers like go-sql-driver/mysql(Number) and go-sqlite3(ExtendedCode) expose codes only as fields.
+func ErrorCode(e error, pkg string, field string) (code int64, found bool) {
+	for ; nil != e; e = errors.Unwrap(e) {
+		v := reflect.ValueOf(e)
+		if reflect.Pointer == v.Kind() {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+		if reflect.Struct != v.Kind() || pkg != v.Type().PkgPath() {
+			continue
+		}
+		f := v.FieldByName(field)
+		switch {
+		case !f.IsValid():
+			continue
+		case f.CanInt():
+			return f.Int(), true
+		case f.CanUint():
+			return int64(f.Uint()), true
+		}
+	}
+	return 0, false
+}
+
+// ErrorFromSqlState converts an error which has SQLSTATE(e.g. *pgconn.PgError)
+// or an error known by registered converters.
+// Returns the original error if it is unknown.
+func ErrorFromSqlState(e error) error {
+	var se sqlStateError
+	if !errors.As(e, &se) {
+		return errorFromConverters(e)
+	}
+	sentinel, found := sqlState2sentinel[se.SQLState()]
+	if found {
+		return ErrorConvertNew(sentinel, e)
+	}
+	return e
+}
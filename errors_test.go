@@ -0,0 +1,146 @@
+package sql2keyval
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type dummySqlStateError struct{ state string }
+
+func (d dummySqlStateError) Error() string    { return "dummy: " + d.state }
+func (d dummySqlStateError) SQLState() string { return d.state }
+
+func TestErrorConvertNew(t *testing.T) {
+	t.Parallel()
+
+	native := fmt.Errorf("native")
+
+	t.Run("both", func(t *testing.T) {
+		t.Parallel()
+		e := ErrorConvertNew(ErrNotFound, native)
+		if !errors.Is(e, ErrNotFound) {
+			t.Errorf("Must be ErrNotFound")
+		}
+		if !errors.Is(e, native) {
+			t.Errorf("Must keep native error")
+		}
+		if errors.Is(e, ErrDuplicateKey) {
+			t.Errorf("Must not be ErrDuplicateKey")
+		}
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		t.Parallel()
+		e := ErrorConvertNew(ErrNotFound, native)
+		if e != ErrorConvertNew(ErrNotFound, e) {
+			t.Errorf("Must not convert twice")
+		}
+	})
+}
+
+func TestErrorFromSqlState(t *testing.T) {
+	t.Parallel()
+
+	pat := []struct {
+		state    string
+		sentinel error
+	}{
+		{state: "42P01", sentinel: ErrBucketNotFound},
+		{state: "23505", sentinel: ErrDuplicateKey},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.state, func(t *testing.T) {
+			t.Parallel()
+			e := ErrorFromSqlState(fmt.Errorf("wrapped: %w", dummySqlStateError{p.state}))
+			if !errors.Is(e, p.sentinel) {
+				t.Errorf("Must be converted: %v", e)
+			}
+		})
+	}
+
+	t.Run("unknown state", func(t *testing.T) {
+		t.Parallel()
+		native := dummySqlStateError{"42000"}
+		if error(native) != ErrorFromSqlState(native) {
+			t.Errorf("Must not be converted")
+		}
+	})
+
+	t.Run("no state", func(t *testing.T) {
+		t.Parallel()
+		native := fmt.Errorf("native")
+		if native != ErrorFromSqlState(native) {
+			t.Errorf("Must not be converted")
+		}
+	})
+}
+
+type dummyCodeError struct{ Code uint16 }
+
+func (d *dummyCodeError) Error() string { return "dummy code" }
+
+func TestErrorCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+		code, found := ErrorCode(fmt.Errorf("wrapped: %w", &dummyCodeError{42}), "github.com/takanoriyanagitani/go-sql2keyval", "Code")
+		if !found || 42 != code {
+			t.Errorf("Unexpected code: %v, %v", code, found)
+		}
+	})
+
+	t.Run("other package", func(t *testing.T) {
+		t.Parallel()
+		_, found := ErrorCode(&dummyCodeError{42}, "mysql", "Code")
+		if found {
+			t.Errorf("Must ignore errors of other packages")
+		}
+	})
+
+	t.Run("partial package path", func(t *testing.T) {
+		t.Parallel()
+		_, found := ErrorCode(&dummyCodeError{42}, "sql2keyval", "Code")
+		if found {
+			t.Errorf("Must match the exact package path")
+		}
+	})
+
+	t.Run("no field", func(t *testing.T) {
+		t.Parallel()
+		_, found := ErrorCode(&dummyCodeError{42}, "github.com/takanoriyanagitani/go-sql2keyval", "Number")
+		if found {
+			t.Errorf("Must ignore errors without the field")
+		}
+		_, found = ErrorCode(fmt.Errorf("native"), "", "Code")
+		if found {
+			t.Errorf("Must ignore errors without the field")
+		}
+	})
+}
+
+func TestRegisterErrorConverter(t *testing.T) {
+	t.Parallel()
+
+	RegisterErrorConverter(nil)
+	RegisterErrorConverter(func(e error) error {
+		code, _ := ErrorCode(e, "github.com/takanoriyanagitani/go-sql2keyval", "Code")
+		if 65535 == code {
+			return ErrConflict
+		}
+		return nil
+	})
+
+	e := ErrorFromSqlState(fmt.Errorf("wrapped: %w", &dummyCodeError{65535}))
+	if !errors.Is(e, ErrConflict) {
+		t.Errorf("Must be converted: %v", e)
+	}
+
+	native := &dummyCodeError{1}
+	if error(native) != ErrorFromSqlState(native) {
+		t.Errorf("Must not be converted")
+	}
+}
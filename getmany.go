@@ -0,0 +1,96 @@
+package sql2keyval
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GetMany gets values of keys; found pairs and missing keys are in the order of keys.
+type GetMany func(ctx context.Context, bucket string, keys [][]byte) (found []Pair, missing [][]byte, e error)
+
+type GetManyQueryGenerator interface {
+	GetMany(bucket string) (query string, e error)
+}
+
+// ByteaArray encodes keys as a postgres bytea array literal so that drivers without array support(e.g. lib/pq) can send it.
+type ByteaArray [][]byte
+
+func (a ByteaArray) Value() (driver.Value, error) {
+	var buf strings.Builder
+	buf.WriteString("{")
+	for i, b := range a {
+		if 0 < i {
+			buf.WriteString(",")
+		}
+		if nil == b {
+			buf.WriteString("NULL")
+			continue
+		}
+		buf.WriteString(`"\\x`)
+		buf.WriteString(hex.EncodeToString(b))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString("}")
+	return buf.String(), nil
+}
+
+func GetManyIter(ctx context.Context, g GetMany, bucket string, keys Iter[[]byte]) (found []Pair, missing [][]byte, e error) {
+	return g(ctx, bucket, keys.ToArray())
+}
+
+// SplitFound splits keys into found pairs and missing keys using got values.
+func SplitFound(keys [][]byte, got map[string][]byte) (found []Pair, missing [][]byte) {
+	for _, key := range keys {
+		val, ok := got[string(key)]
+		if ok {
+			found = append(found, Pair{Key: key, Val: val})
+			continue
+		}
+		missing = append(missing, key)
+	}
+	return
+}
+
+func getManyNew(g GetManyQueryGenerator, q QueryCb) GetMany {
+	return func(ctx context.Context, bucket string, keys [][]byte) (found []Pair, missing [][]byte, e error) {
+		if 0 == len(keys) {
+			return nil, nil, nil
+		}
+
+		query, e := g.GetMany(bucket)
+		if nil != e {
+			return nil, nil, fmt.Errorf("Unable to get query for multi get: %w", e)
+		}
+
+		got := make(map[string][]byte, len(keys))
+		e = q(
+			ctx,
+			func(r Record) error {
+				p, e := record2pair(r)
+				if nil != e {
+					return fmt.Errorf("Unable to get key/val as byte arrays: %w", e)
+				}
+				got[string(p.Key)] = p.Val
+				return nil
+			},
+			query,
+			ByteaArray(keys),
+		)
+		if nil != e {
+			return nil, nil, e
+		}
+
+		found, missing = SplitFound(keys, got)
+		return
+	}
+}
+
+var getManyFactory func(GetManyQueryGenerator) func(QueryCb) GetMany = curry(getManyNew)
+
+var GetManyFactory func(driverName string) func(QueryCb) GetMany = compose(
+	getQueryGeneratorExtOrEmpty[GetManyQueryGenerator],
+	getManyFactory,
+)
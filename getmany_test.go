@@ -0,0 +1,70 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetManyFactory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var dummyFactory func(QueryCb) GetMany = GetManyFactory("does-not-exist")
+		var getter GetMany = dummyFactory(nil)
+		_, _, e := getter(context.Background(), "", [][]byte{[]byte("k")})
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		var getter GetMany = GetManyFactory("does-not-exist")(nil)
+		found, missing, e := getter(context.Background(), "", nil)
+		if nil != e {
+			t.Errorf("Should be nop: %v", e)
+		}
+		if 0 != len(found) || 0 != len(missing) {
+			t.Errorf("Must be empty")
+		}
+	})
+}
+
+func TestSplitFound(t *testing.T) {
+	t.Parallel()
+
+	keys := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	got := map[string][]byte{
+		"a": []byte("va"),
+		"c": []byte("vc"),
+	}
+
+	found, missing := SplitFound(keys, got)
+	if 2 != len(found) {
+		t.Fatalf("Unexpected number of found pairs: %v", len(found))
+	}
+	if "a" != string(found[0].Key) || "vc" != string(found[1].Val) {
+		t.Errorf("Unexpected pairs: %v", found)
+	}
+	if 1 != len(missing) || "b" != string(missing[0]) {
+		t.Errorf("Unexpected missing keys: %v", missing)
+	}
+}
+
+func TestByteaArray(t *testing.T) {
+	t.Parallel()
+
+	v, e := ByteaArray([][]byte{[]byte("ab"), {}, nil}).Value()
+	if nil != e {
+		t.Fatalf("Unexpected error: %v", e)
+	}
+	if `{"\\x6162","\\x",NULL}` != v {
+		t.Errorf("Unexpected literal: %v", v)
+	}
+
+	v, e = ByteaArray(nil).Value()
+	if nil != e || "{}" != v {
+		t.Errorf("Unexpected literal: %v, %v", v, e)
+	}
+}
@@ -2,11 +2,13 @@ module github.com/takanoriyanagitani/go-sql2keyval
 
 go 1.19
 
-require github.com/jackc/pgx/v4 v4.17.1
+require (
+	github.com/jackc/pgconn v1.13.0
+	github.com/jackc/pgx/v4 v4.17.1
+)
 
 require (
 	github.com/jackc/chunkreader/v2 v2.0.1 // indirect
-	github.com/jackc/pgconn v1.13.0 // indirect
 	github.com/jackc/pgio v1.0.0 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgproto3/v2 v2.3.1 // indirect
@@ -0,0 +1,52 @@
+package sql2keyval
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+// Incr atomically adds delta to the counter(missing key: 0) and returns the new value.
+//
+// Counters are stored as 8 byte big endian two's complement integers(see CounterEncode).
+// Returns ErrInvalidValue if the current value is not a counter.
+type Incr func(ctx context.Context, bucket string, key []byte, delta int64) (int64, error)
+
+type IncrQueryGenerator interface {
+	// Incr: $1 key, $2 delta; returns the new value(no row if the current value is not a counter)
+	Incr(bucket string) (query string, e error)
+}
+
+// CounterEncode encodes the counter into 8 byte big endian bytes.
+func CounterEncode(i int64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(i))
+	return buf[:]
+}
+
+// CounterDecode decodes the counter encoded by CounterEncode.
+func CounterDecode(b []byte) (int64, error) {
+	if 8 != len(b) {
+		return 0, fmt.Errorf("Invalid counter(len=%v): %w", len(b), ErrInvalidValue)
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func incrNew(g IncrQueryGenerator, q Query) Incr {
+	return func(ctx context.Context, bucket string, key []byte, delta int64) (i int64, e error) {
+		query, e := g.Incr(bucket)
+		if nil != e {
+			return 0, e
+		}
+		e = q(ctx, query, key, delta).Scan(&i)
+		return i, notFound2invalid(e)
+	}
+}
+
+// notFound2invalid converts ErrNotFound(no row updated) into ErrInvalidValue.
+func notFound2invalid(e error) error { return ErrorReplaceNew(ErrNotFound, ErrInvalidValue, e) }
+
+var IncrFactory func(driverName string) func(Query) Incr = compose(
+	getQueryGeneratorExtOrEmpty[IncrQueryGenerator],
+	curry(incrNew),
+)
@@ -0,0 +1,72 @@
+package sql2keyval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode", func(t *testing.T) {
+		t.Parallel()
+		got := CounterEncode(-2)
+		expected := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+		if !bytes.Equal(got, expected) {
+			t.Errorf("Unexpected bytes: %v", got)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		for _, i := range []int64{0, 1, -1, 634, -9223372036854775808, 9223372036854775807} {
+			got, e := CounterDecode(CounterEncode(i))
+			if nil != e {
+				t.Errorf("Unexpected error: %v", e)
+			}
+			if i != got {
+				t.Errorf("Unexpected value. Expected: %v, Got: %v", i, got)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, e := CounterDecode([]byte("abc"))
+		if !errors.Is(e, ErrInvalidValue) {
+			t.Errorf("Must be ErrInvalidValue: %v", e)
+		}
+	})
+}
+
+func TestIncrFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("incr-dummy", &emptyQueryGenerator{})
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var i Incr = IncrFactory("does-not-exist")(nil)
+		_, e := i(context.Background(), "b", nil, 1)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("not a counter", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record {
+			return dummyRecord{ErrNotFound}
+		}
+		var i Incr = IncrFactory("incr-dummy")(q)
+		_, e := i(context.Background(), "b", []byte("k"), 1)
+		if !errors.Is(e, ErrInvalidValue) {
+			t.Errorf("Must be ErrInvalidValue: %v", e)
+		}
+		if errors.Is(e, ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+}
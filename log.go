@@ -0,0 +1,53 @@
+package sql2keyval
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LogRecord is an entry of an append-only log bucket.
+type LogRecord struct {
+	Id      int64
+	Payload []byte
+}
+
+// LogRead gets at most limit records whose id is greater than afterId in id order.
+type LogRead func(ctx context.Context, bucket string, afterId int64, limit int64) ([]LogRecord, error)
+
+// LogTail passes records whose id is greater than afterId to cb in id order until ctx is done or cb fails.
+type LogTail func(ctx context.Context, bucket string, afterId int64, cb func(LogRecord) error) error
+
+// LogTailNew creates LogTail which reads batches of limit records and waits interval when caught up.
+func LogTailNew(r LogRead, interval time.Duration, limit int64) LogTail {
+	return func(ctx context.Context, bucket string, afterId int64, cb func(LogRecord) error) error {
+		if limit < 1 {
+			return fmt.Errorf("Invalid limit: %v", limit)
+		}
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+			records, e := r(ctx, bucket, afterId, limit)
+			if nil != e {
+				return e
+			}
+			for _, rec := range records {
+				e = cb(rec)
+				if nil != e {
+					return e
+				}
+				afterId = rec.Id
+			}
+			if int64(len(records)) < limit {
+				timer.Reset(interval)
+			} else {
+				timer.Reset(0)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogTail(t *testing.T) {
+	t.Parallel()
+
+	var logs [][]byte = [][]byte{
+		[]byte("l1"),
+		[]byte("l2"),
+		[]byte("l3"),
+	}
+	var r LogRead = func(_ context.Context, _ string, afterId int64, limit int64) ([]LogRecord, error) {
+		var records []LogRecord
+		for i := afterId; i < int64(len(logs)) && int64(len(records)) < limit; i++ {
+			records = append(records, LogRecord{Id: i + 1, Payload: logs[i]})
+		}
+		return records, nil
+	}
+
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Parallel()
+		e := LogTailNew(r, time.Millisecond, 0)(context.Background(), "l0", 0, nil)
+		if nil == e {
+			t.Errorf("Must reject invalid limit")
+		}
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		t.Parallel()
+		errStop := errors.New("stop")
+		var ids []int64
+		e := LogTailNew(r, time.Millisecond, 2)(context.Background(), "l0", 1, func(rec LogRecord) error {
+			ids = append(ids, rec.Id)
+			if 3 == rec.Id {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(e, errStop) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 2 != len(ids) || 2 != ids[0] || 3 != ids[1] {
+			t.Errorf("Unexpected ids: %v", ids)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var cnt int
+		e := LogTailNew(r, time.Millisecond, 2)(ctx, "l0", 0, func(_ LogRecord) error {
+			cnt += 1
+			return nil
+		})
+		if !errors.Is(e, context.DeadlineExceeded) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 3 != cnt {
+			t.Errorf("Unexpected count: %v", cnt)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		t.Parallel()
+		var bad LogRead = func(_ context.Context, _ string, _ int64, _ int64) ([]LogRecord, error) {
+			return nil, ErrBucketNotFound
+		}
+		e := LogTailNew(bad, time.Millisecond, 2)(context.Background(), "l0", 0, nil)
+		if !errors.Is(e, ErrBucketNotFound) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+}
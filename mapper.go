@@ -0,0 +1,37 @@
+package sql2keyval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// BucketMapper maps the logical bucket name to the physical table name.
+type BucketMapper func(bucket string) (table string, e error)
+
+func BucketMapperIdentity(bucket string) (table string, e error) { return bucket, nil }
+
+// HashMapperNew keeps names up to maxLen bytes and maps longer names to prefix + truncated sha256(hex).
+// The namespace part("namespace.") is kept as is.
+func HashMapperNew(prefix string, maxLen int) BucketMapper {
+	hashLen := maxLen - len(prefix)
+	return func(bucket string) (table string, e error) {
+		namespace, name, found := strings.Cut(bucket, ".")
+		if !found {
+			namespace, name = "", bucket
+		}
+		if len(name) <= maxLen {
+			return bucket, nil
+		}
+		if hashLen < 8 || sha256.Size*2 < hashLen {
+			return "", fmt.Errorf("Invalid hash length(%v): %w", hashLen, ErrInvalidBucket)
+		}
+		sum := sha256.Sum256([]byte(name))
+		table = prefix + hex.EncodeToString(sum[:])[:hashLen]
+		if found {
+			return namespace + "." + table, nil
+		}
+		return table, nil
+	}
+}
@@ -0,0 +1,63 @@
+package sql2keyval
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashMapperNew(t *testing.T) {
+	t.Parallel()
+
+	var m BucketMapper = HashMapperNew("h_", 59)
+	long := "very_long_bucket_name_" + strings.Repeat("x", 64)
+
+	t.Run("short", func(t *testing.T) {
+		t.Parallel()
+		got, e := m("ns.short")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if "ns.short" != got {
+			t.Errorf("Must keep short name: %s", got)
+		}
+	})
+
+	t.Run("long", func(t *testing.T) {
+		t.Parallel()
+		got, e := m(long)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 59 != len(got) || !strings.HasPrefix(got, "h_") {
+			t.Errorf("Unexpected table: %s", got)
+		}
+		again, _ := m(long)
+		if got != again {
+			t.Errorf("Must be deterministic: %s, %s", got, again)
+		}
+		other, _ := m(long + "y")
+		if got == other {
+			t.Errorf("Must differ: %s", got)
+		}
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		t.Parallel()
+		got, e := m("ns." + long)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if !strings.HasPrefix(got, "ns.h_") {
+			t.Errorf("Must keep namespace: %s", got)
+		}
+	})
+
+	t.Run("too short hash", func(t *testing.T) {
+		t.Parallel()
+		_, e := HashMapperNew("prefix_", 8)(long)
+		if !errors.Is(e, ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+// AddNamespace creates the namespace which holds buckets named "namespace.bucket".
+type AddNamespace func(ctx context.Context, namespace string) error
+
+// DropNamespace drops the namespace(fails if it still has buckets).
+type DropNamespace func(ctx context.Context, namespace string) error
+
+type NamespaceQueryGenerator interface {
+	AddNamespace(namespace string) (query string, e error)
+	DropNamespace(namespace string) (query string, e error)
+}
+
+func addNamespaceNew(g NamespaceQueryGenerator, x Exec) AddNamespace {
+	return func(ctx context.Context, namespace string) error {
+		query, e := g.AddNamespace(namespace)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+func dropNamespaceNew(g NamespaceQueryGenerator, x Exec) DropNamespace {
+	return func(ctx context.Context, namespace string) error {
+		query, e := g.DropNamespace(namespace)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+var AddNamespaceFactory func(driverName string) func(Exec) AddNamespace = compose(
+	getQueryGeneratorExtOrEmpty[NamespaceQueryGenerator],
+	curry(addNamespaceNew),
+)
+
+var DropNamespaceFactory func(driverName string) func(Exec) DropNamespace = compose(
+	getQueryGeneratorExtOrEmpty[NamespaceQueryGenerator],
+	curry(dropNamespaceNew),
+)
@@ -0,0 +1,37 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNamespaceFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("namespace-dummy", &emptyQueryGenerator{})
+
+	var x Exec = func(_ context.Context, _ string, _ ...any) error { return nil }
+
+	t.Run("AddNamespace", func(t *testing.T) {
+		t.Parallel()
+		var a AddNamespace = AddNamespaceFactory("does-not-exist")(x)
+		e := a(context.Background(), "ns")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+		a = AddNamespaceFactory("namespace-dummy")(x)
+		e = a(context.Background(), "ns")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+
+	t.Run("DropNamespace", func(t *testing.T) {
+		t.Parallel()
+		var d DropNamespace = DropNamespaceFactory("does-not-exist")(x)
+		e := d(context.Background(), "ns")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
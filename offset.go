@@ -0,0 +1,32 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+// OffsetCommit saves the last processed id of the log for the consumer group.
+type OffsetCommit func(ctx context.Context, bucket string, group string, id int64) error
+
+// OffsetPosition gets the last committed id of the log for the consumer group(0 if never committed).
+type OffsetPosition func(ctx context.Context, bucket string, group string) (id int64, e error)
+
+// LogConsume passes records after the committed position of the group to cb until ctx is done or cb fails.
+type LogConsume func(ctx context.Context, bucket string, group string, cb func(LogRecord) error) error
+
+// LogConsumeNew creates an at-least-once LogConsume which commits each record after cb succeeds.
+// Records may be passed again if the commit fails after cb.
+func LogConsumeNew(tail LogTail, pos OffsetPosition, commit OffsetCommit) LogConsume {
+	return func(ctx context.Context, bucket string, group string, cb func(LogRecord) error) error {
+		afterId, e := pos(ctx, bucket, group)
+		if nil != e {
+			return e
+		}
+		return tail(ctx, bucket, afterId, func(rec LogRecord) error {
+			e := cb(rec)
+			if nil != e {
+				return e
+			}
+			return commit(ctx, bucket, group, rec.Id)
+		})
+	}
+}
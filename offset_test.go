@@ -0,0 +1,78 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLogConsume(t *testing.T) {
+	t.Parallel()
+
+	var tail LogTail = func(_ context.Context, _ string, afterId int64, cb func(LogRecord) error) error {
+		for i := afterId + 1; i <= 3; i++ {
+			e := cb(LogRecord{Id: i})
+			if nil != e {
+				return e
+			}
+		}
+		return nil
+	}
+
+	t.Run("commit after cb", func(t *testing.T) {
+		t.Parallel()
+
+		offsets := map[string]int64{"g0": 1}
+		var pos OffsetPosition = func(_ context.Context, _ string, group string) (int64, error) {
+			return offsets[group], nil
+		}
+		var commit OffsetCommit = func(_ context.Context, _ string, group string, id int64) error {
+			offsets[group] = id
+			return nil
+		}
+
+		errStop := errors.New("stop")
+		var ids []int64
+		e := LogConsumeNew(tail, pos, commit)(context.Background(), "l0", "g0", func(rec LogRecord) error {
+			if 3 == rec.Id {
+				return errStop
+			}
+			ids = append(ids, rec.Id)
+			return nil
+		})
+		if !errors.Is(e, errStop) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 1 != len(ids) || 2 != ids[0] {
+			t.Errorf("Unexpected ids: %v", ids)
+		}
+		if 2 != offsets["g0"] {
+			t.Errorf("Unexpected offset: %v", offsets["g0"])
+		}
+
+		// restarts from the committed position
+		ids = nil
+		e = LogConsumeNew(tail, pos, commit)(context.Background(), "l0", "g0", func(rec LogRecord) error {
+			ids = append(ids, rec.Id)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 1 != len(ids) || 3 != ids[0] || 3 != offsets["g0"] {
+			t.Errorf("Unexpected ids/offset: %v/%v", ids, offsets["g0"])
+		}
+	})
+
+	t.Run("position error", func(t *testing.T) {
+		t.Parallel()
+
+		var pos OffsetPosition = func(_ context.Context, _ string, _ string) (int64, error) {
+			return 0, ErrBucketNotFound
+		}
+		e := LogConsumeNew(tail, pos, nil)(context.Background(), "l0", "g0", nil)
+		if !errors.Is(e, ErrBucketNotFound) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+type PartitionQueryGenerator interface {
+	// AddPartitionedBucket: creates the bucket hash-partitioned by key into partitions tables
+	AddPartitionedBucket(bucket string, partitions int) (query string, e error)
+}
+
+func addPartitionedBucketNew(g PartitionQueryGenerator, x Exec, partitions int) AddBucket {
+	return func(ctx context.Context, bucket string) error {
+		query, e := g.AddPartitionedBucket(bucket, partitions)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+// AddPartitionedBucketFactory creates AddBucket which creates hash-partitioned buckets.
+// Other operations(Get, Set, Lst, ...) work on the partitioned bucket as is.
+func AddPartitionedBucketFactory(driverName string, partitions int) func(Exec) AddBucket {
+	var g PartitionQueryGenerator = getQueryGeneratorExtOrEmpty[PartitionQueryGenerator](driverName)
+	return func(x Exec) AddBucket {
+		return addPartitionedBucketNew(g, x, partitions)
+	}
+}
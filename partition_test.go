@@ -0,0 +1,32 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAddPartitionedBucketFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("partition-dummy", &emptyQueryGenerator{})
+
+	var x Exec = func(_ context.Context, _ string, _ ...any) error { return nil }
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var a AddBucket = AddPartitionedBucketFactory("does-not-exist", 4)(x)
+		e := a(context.Background(), "b")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		t.Parallel()
+		var a AddBucket = AddPartitionedBucketFactory("partition-dummy", 4)(x)
+		e := a(context.Background(), "b")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+}
@@ -0,0 +1,435 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// Db keeps buckets and logs in memory; safe for concurrent use.
+type Db struct {
+	lock    sync.RWMutex
+	buckets map[string]map[string][]byte
+	logs    map[string][][]byte
+}
+
+func DbNew() *Db {
+	return &Db{
+		buckets: make(map[string]map[string][]byte),
+		logs:    make(map[string][][]byte),
+	}
+}
+
+// same rule as the postgres generator
+var bucketPattern = regexp.MustCompile(`^[a-z][0-9a-z_]{0,58}$`)
+
+func validateBucket(bucket string) error {
+	return s2k.Bool2error(
+		bucketPattern.MatchString(bucket),
+		func() error {
+			return fmt.Errorf("Invalid bucket name(%s): %w", bucket, s2k.ErrInvalidBucket)
+		},
+	)
+}
+
+func validatePair(key, val []byte) error {
+	if nil == key {
+		return fmt.Errorf("Invalid key: nil")
+	}
+	if nil == val {
+		return fmt.Errorf("Invalid val: nil")
+	}
+	return nil
+}
+
+func clone(b []byte) []byte {
+	if nil == b {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// bucket gets the bucket; caller must hold the lock.
+func (d *Db) bucket(name string) (map[string][]byte, error) {
+	e := validateBucket(name)
+	if nil != e {
+		return nil, e
+	}
+	b, found := d.buckets[name]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", s2k.ErrBucketNotFound, name)
+	}
+	return b, nil
+}
+
+// sortedPairs gets a snapshot of the bucket sorted by key.
+func (d *Db) sortedPairs(name string) ([]s2k.Pair, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	b, e := d.bucket(name)
+	if nil != e {
+		return nil, e
+	}
+
+	pairs := make([]s2k.Pair, 0, len(b))
+	for k, v := range b {
+		pairs = append(pairs, s2k.Pair{Key: []byte(k), Val: clone(v)})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].Key, pairs[j].Key) < 0
+	})
+	return pairs, nil
+}
+
+func GetNew(d *Db) s2k.Get {
+	return func(_ context.Context, bucket string, key []byte) ([]byte, error) {
+		d.lock.RLock()
+		defer d.lock.RUnlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return nil, e
+		}
+		val, found := b[string(key)]
+		if !found {
+			return nil, s2k.ErrNotFound
+		}
+		return clone(val), nil
+	}
+}
+
+func DelNew(d *Db) s2k.Del {
+	return func(_ context.Context, bucket string, key []byte) error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return e
+		}
+		delete(b, string(key))
+		return nil
+	}
+}
+
+func AddNew(d *Db) s2k.Add {
+	return func(_ context.Context, bucket string, key, val []byte) error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return e
+		}
+		e = validatePair(key, val)
+		if nil != e {
+			return e
+		}
+		_, found := b[string(key)]
+		if found {
+			return fmt.Errorf("%w: %s", s2k.ErrDuplicateKey, key)
+		}
+		b[string(key)] = clone(val)
+		return nil
+	}
+}
+
+func SetNew(d *Db) s2k.Set {
+	return func(_ context.Context, bucket string, key, val []byte) error {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return e
+		}
+		e = validatePair(key, val)
+		if nil != e {
+			return e
+		}
+		b[string(key)] = clone(val)
+		return nil
+	}
+}
+
+func LstNew(d *Db) s2k.Lst {
+	return func(_ context.Context, bucket string, cb func(key []byte) error) error {
+		pairs, e := d.sortedPairs(bucket)
+		if nil != e {
+			return e
+		}
+		for _, p := range pairs {
+			e = cb(p.Key)
+			if nil != e {
+				return e
+			}
+		}
+		return nil
+	}
+}
+
+func inRange(r s2k.Range, key []byte) bool {
+	lower := r.Lower()
+	upper := r.Upper()
+	return (nil == lower || bytes.Compare(lower, key) <= 0) &&
+		(nil == upper || bytes.Compare(key, upper) < 0)
+}
+
+func LstRangeNew(d *Db) s2k.LstRange {
+	return func(_ context.Context, bucket string, r s2k.Range, cb func(key []byte) error) error {
+		pairs, e := d.sortedPairs(bucket)
+		if nil != e {
+			return e
+		}
+		if r.Reverse {
+			for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+				pairs[i], pairs[j] = pairs[j], pairs[i]
+			}
+		}
+		var cnt int64 = 0
+		for _, p := range pairs {
+			if 0 < r.Limit && r.Limit <= cnt {
+				return nil
+			}
+			if !inRange(r, p.Key) {
+				continue
+			}
+			e = cb(p.Key)
+			if nil != e {
+				return e
+			}
+			cnt += 1
+		}
+		return nil
+	}
+}
+
+func ScanNew(d *Db) s2k.Scan {
+	return func(_ context.Context, bucket string, cb func(p s2k.Pair) error) error {
+		pairs, e := d.sortedPairs(bucket)
+		if nil != e {
+			return e
+		}
+		for _, p := range pairs {
+			e = cb(p)
+			if nil != e {
+				return e
+			}
+		}
+		return nil
+	}
+}
+
+func GetManyNew(d *Db) s2k.GetMany {
+	return func(_ context.Context, bucket string, keys [][]byte) ([]s2k.Pair, [][]byte, error) {
+		if 0 == len(keys) {
+			return nil, nil, nil
+		}
+
+		d.lock.RLock()
+		defer d.lock.RUnlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return nil, nil, e
+		}
+
+		got := make(map[string][]byte, len(keys))
+		for _, key := range keys {
+			val, found := b[string(key)]
+			if found {
+				got[string(key)] = clone(val)
+			}
+		}
+		found, missing := s2k.SplitFound(keys, got)
+		return found, missing, nil
+	}
+}
+
+func AddBucketNew(d *Db) s2k.AddBucket {
+	return func(_ context.Context, bucket string) error {
+		e := validateBucket(bucket)
+		if nil != e {
+			return e
+		}
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		_, found := d.buckets[bucket]
+		if !found {
+			d.buckets[bucket] = make(map[string][]byte)
+		}
+		return nil
+	}
+}
+
+func DelBucketNew(d *Db) s2k.DelBucket {
+	return func(_ context.Context, bucket string) error {
+		e := validateBucket(bucket)
+		if nil != e {
+			return e
+		}
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		delete(d.buckets, bucket)
+		return nil
+	}
+}
+
+// SetManyNew creates SetMany which sets all pairs or nothing.
+func SetManyNew(d *Db) s2k.SetMany {
+	return func(_ context.Context, bucket string, pairs []s2k.Pair) error {
+		if 0 == len(pairs) {
+			return nil
+		}
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		b, e := d.bucket(bucket)
+		if nil != e {
+			return e
+		}
+		for _, p := range pairs {
+			e = validatePair(p.Key, p.Val)
+			if nil != e {
+				return e
+			}
+		}
+		for _, p := range pairs {
+			b[string(p.Key)] = clone(p.Val)
+		}
+		return nil
+	}
+}
+
+// SetBatchNew creates SetBatch which sets all pairs or nothing.
+func SetBatchNew(d *Db) s2k.SetBatch {
+	return func(_ context.Context, many s2k.Iter[s2k.Batch]) error {
+		batches := many.ToArray()
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		for _, b := range batches {
+			_, e := d.bucket(b.Bucket())
+			if nil != e {
+				return e
+			}
+			e = validatePair(b.Pair().Key, b.Pair().Val)
+			if nil != e {
+				return e
+			}
+		}
+		for _, b := range batches {
+			d.buckets[b.Bucket()][string(b.Pair().Key)] = clone(b.Pair().Val)
+		}
+		return nil
+	}
+}
+
+func SetMany2BucketBuilder(bucket string) func(d *Db) s2k.SetMany2Bucket {
+	return func(d *Db) s2k.SetMany2Bucket {
+		var sm s2k.SetMany = SetManyNew(d)
+		return func(ctx context.Context, pairs []s2k.Pair) error {
+			return sm(ctx, bucket, pairs)
+		}
+	}
+}
+
+func Pairs2BucketBuilder(bucket string) func(d *Db) s2k.Pairs2Bucket {
+	return func(d *Db) s2k.Pairs2Bucket {
+		var sm s2k.SetMany = SetManyNew(d)
+		return func(ctx context.Context, pairs s2k.Iter[s2k.Pair]) error {
+			return sm(ctx, bucket, pairs.ToArray())
+		}
+	}
+}
+
+func AddLogNew(d *Db) s2k.AddLog {
+	return func(_ context.Context, bucket string) error {
+		e := validateBucket(bucket)
+		if nil != e {
+			return e
+		}
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		_, found := d.logs[bucket]
+		if !found {
+			d.logs[bucket] = nil
+		}
+		return nil
+	}
+}
+
+func InsLogBuilder(bucket string) func(d *Db) s2k.InsLog {
+	return func(d *Db) s2k.InsLog {
+		return func(_ context.Context, lg []byte) error {
+			e := validateBucket(bucket)
+			if nil != e {
+				return e
+			}
+
+			d.lock.Lock()
+			defer d.lock.Unlock()
+
+			logs, found := d.logs[bucket]
+			if !found {
+				return fmt.Errorf("%w: %s", s2k.ErrBucketNotFound, bucket)
+			}
+			d.logs[bucket] = append(logs, clone(lg))
+			return nil
+		}
+	}
+}
+
+// LogReadNew creates LogRead; ids start from 1 in insertion order.
+func LogReadNew(d *Db) s2k.LogRead {
+	return func(_ context.Context, bucket string, afterId int64, limit int64) ([]s2k.LogRecord, error) {
+		e := validateBucket(bucket)
+		if nil != e {
+			return nil, e
+		}
+
+		d.lock.RLock()
+		defer d.lock.RUnlock()
+
+		logs, found := d.logs[bucket]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", s2k.ErrBucketNotFound, bucket)
+		}
+		if afterId < 0 {
+			afterId = 0
+		}
+		var records []s2k.LogRecord
+		for i := afterId; i < int64(len(logs)) && int64(len(records)) < limit; i++ {
+			records = append(records, s2k.LogRecord{Id: i + 1, Payload: clone(logs[i])})
+		}
+		return records, nil
+	}
+}
+
+func StoreNew(d *Db) s2k.Store {
+	return s2k.Store{
+		Get:       GetNew(d),
+		Set:       SetNew(d),
+		Add:       AddNew(d),
+		Del:       DelNew(d),
+		Lst:       LstNew(d),
+		AddBucket: AddBucketNew(d),
+		DelBucket: DelBucketNew(d),
+	}
+}
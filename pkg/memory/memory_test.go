@@ -0,0 +1,356 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func checkBytes(t *testing.T, got, expected []byte) {
+	if 0 != bytes.Compare(got, expected) {
+		t.Errorf("Unexpected value got.\n")
+		t.Errorf("expected: %v\n", expected)
+		t.Errorf("got:      %v\n", got)
+	}
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+		var s s2k.Store = StoreNew(DbNew())
+		e := s.AddBucket(ctx, "0table")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		t.Parallel()
+		var s s2k.Store = StoreNew(DbNew())
+		_, e := s.Get(ctx, "b0", []byte("k"))
+		if !errors.Is(e, s2k.ErrBucketNotFound) {
+			t.Errorf("Must be ErrBucketNotFound: %v", e)
+		}
+		e = s.Set(ctx, "b0", []byte("k"), []byte("v"))
+		if !errors.Is(e, s2k.ErrBucketNotFound) {
+			t.Errorf("Must be ErrBucketNotFound: %v", e)
+		}
+	})
+
+	// non parallel
+	t.Run("ordered", func(t *testing.T) {
+		var s s2k.Store = StoreNew(DbNew())
+		const b = "b0"
+
+		t.Run("add bucket", func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				e := s.AddBucket(ctx, b)
+				if nil != e {
+					t.Errorf("Unable to add bucket: %v", e)
+				}
+			}
+		})
+
+		t.Run("get(before add)", func(t *testing.T) {
+			_, e := s.Get(ctx, b, []byte("k"))
+			if !errors.Is(e, s2k.ErrNotFound) {
+				t.Errorf("Must be ErrNotFound: %v", e)
+			}
+		})
+
+		t.Run("add", func(t *testing.T) {
+			e := s.Add(ctx, b, []byte("k"), []byte("v"))
+			if nil != e {
+				t.Errorf("Unable to add: %v", e)
+			}
+			e = s.Add(ctx, b, []byte("k"), []byte("w"))
+			if !errors.Is(e, s2k.ErrDuplicateKey) {
+				t.Errorf("Must be ErrDuplicateKey: %v", e)
+			}
+		})
+
+		t.Run("set", func(t *testing.T) {
+			val := []byte("w")
+			e := s.Set(ctx, b, []byte("k"), val)
+			if nil != e {
+				t.Errorf("Unable to set: %v", e)
+			}
+			val[0] = 'x' // must be copied
+
+			got, e := s.Get(ctx, b, []byte("k"))
+			if nil != e {
+				t.Errorf("Unable to get: %v", e)
+			}
+			checkBytes(t, got, []byte("w"))
+		})
+
+		t.Run("invalid key", func(t *testing.T) {
+			e := s.Set(ctx, b, nil, []byte("v"))
+			if nil == e {
+				t.Errorf("Must reject invalid key")
+			}
+		})
+
+		t.Run("lst", func(t *testing.T) {
+			_ = s.Set(ctx, b, []byte("a"), []byte("v"))
+			var keys []string
+			e := s.Lst(ctx, b, func(key []byte) error {
+				keys = append(keys, string(key))
+				return nil
+			})
+			if nil != e {
+				t.Errorf("Unable to list: %v", e)
+			}
+			if "a,k" != strings.Join(keys, ",") {
+				t.Errorf("Unexpected keys: %v", keys)
+			}
+		})
+
+		t.Run("del", func(t *testing.T) {
+			e := s.Del(ctx, b, []byte("k"))
+			if nil != e {
+				t.Errorf("Unable to delete: %v", e)
+			}
+			_, e = s.Get(ctx, b, []byte("k"))
+			if !errors.Is(e, s2k.ErrNotFound) {
+				t.Errorf("Must be ErrNotFound: %v", e)
+			}
+		})
+
+		t.Run("del bucket", func(t *testing.T) {
+			e := s.DelBucket(ctx, b)
+			if nil != e {
+				t.Errorf("Unable to delete bucket: %v", e)
+			}
+			_, e = s.Get(ctx, b, []byte("a"))
+			if !errors.Is(e, s2k.ErrBucketNotFound) {
+				t.Errorf("Must be ErrBucketNotFound: %v", e)
+			}
+		})
+	})
+}
+
+func TestListing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	d := DbNew()
+	_ = AddBucketNew(d)(ctx, "b0")
+	e := SetManyNew(d)(ctx, "b0", []s2k.Pair{
+		{Key: []byte("a0"), Val: []byte("v0")},
+		{Key: []byte("b0"), Val: []byte("v3")},
+		{Key: []byte("a2"), Val: []byte("v2")},
+		{Key: []byte("a1"), Val: []byte("v1")},
+	})
+	if nil != e {
+		t.Fatalf("Unable to set: %v", e)
+	}
+
+	t.Run("LstRange", func(t *testing.T) {
+		t.Parallel()
+		pat := []struct {
+			r        s2k.Range
+			n        string
+			expected string
+		}{
+			{r: s2k.Range{Prefix: []byte("a")}, n: "prefix", expected: "a0,a1,a2"},
+			{r: s2k.Range{Start: []byte("a1"), End: []byte("b0")}, n: "start/end", expected: "a1,a2"},
+			{r: s2k.Range{Limit: 2, Reverse: true}, n: "reverse limit", expected: "b0,a2"},
+		}
+		for _, p := range pat {
+			p := p
+			t.Run(p.n, func(t *testing.T) {
+				t.Parallel()
+				var keys []string
+				e := LstRangeNew(d)(ctx, "b0", p.r, func(key []byte) error {
+					keys = append(keys, string(key))
+					return nil
+				})
+				if nil != e {
+					t.Errorf("Unable to list: %v", e)
+				}
+				if p.expected != strings.Join(keys, ",") {
+					t.Errorf("Unexpected keys: %v", keys)
+				}
+			})
+		}
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		t.Parallel()
+		var vals []string
+		e := ScanNew(d)(ctx, "b0", func(p s2k.Pair) error {
+			vals = append(vals, string(p.Val))
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to scan: %v", e)
+		}
+		if "v0,v1,v2,v3" != strings.Join(vals, ",") {
+			t.Errorf("Unexpected vals: %v", vals)
+		}
+	})
+
+	t.Run("GetMany", func(t *testing.T) {
+		t.Parallel()
+		found, missing, e := GetManyNew(d)(ctx, "b0", [][]byte{[]byte("a0"), []byte("zz")})
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		if 1 != len(found) || 1 != len(missing) {
+			t.Fatalf("Unexpected result: %v, %v", found, missing)
+		}
+		checkBytes(t, found[0].Val, []byte("v0"))
+		checkBytes(t, missing[0], []byte("zz"))
+	})
+}
+
+func TestBulk(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("SetMany partial invalid key", func(t *testing.T) {
+		t.Parallel()
+		d := DbNew()
+		_ = AddBucketNew(d)(ctx, "b0")
+		e := SetMany2BucketBuilder("b0")(d)(ctx, []s2k.Pair{
+			{Key: []byte("k"), Val: []byte("v")},
+			{Key: nil, Val: []byte("v")},
+		})
+		if nil == e {
+			t.Errorf("Must reject invalid key")
+		}
+		_, e = GetNew(d)(ctx, "b0", []byte("k"))
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be rolled back: %v", e)
+		}
+	})
+
+	t.Run("Pairs2Bucket", func(t *testing.T) {
+		t.Parallel()
+		d := DbNew()
+		_ = AddBucketNew(d)(ctx, "b0")
+		e := Pairs2BucketBuilder("b0")(d)(ctx, s2k.IterFromArray([]s2k.Pair{
+			{Key: []byte("k"), Val: []byte("v")},
+		}))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+	})
+
+	t.Run("SetBatch", func(t *testing.T) {
+		t.Parallel()
+		d := DbNew()
+		_ = AddBucketNew(d)(ctx, "b0")
+		_ = AddBucketNew(d)(ctx, "b1")
+
+		var sb s2k.SetBatch = SetBatchNew(d)
+		e := sb(ctx, s2k.IterFromArray([]s2k.Batch{
+			s2k.BatchNew("b0", []byte("k"), []byte("v")),
+			s2k.BatchNew("b2", []byte("k"), []byte("v")),
+		}))
+		if !errors.Is(e, s2k.ErrBucketNotFound) {
+			t.Errorf("Must be ErrBucketNotFound: %v", e)
+		}
+
+		e = sb(ctx, s2k.IterFromArray([]s2k.Batch{
+			s2k.BatchNew("b0", []byte("k"), []byte("v")),
+			s2k.BatchNew("b1", []byte("k"), []byte("w")),
+		}))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, _ := GetNew(d)(ctx, "b1", []byte("k"))
+		checkBytes(t, got, []byte("w"))
+	})
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	d := DbNew()
+
+	e := InsLogBuilder("l0")(d)(ctx, []byte("lg"))
+	if !errors.Is(e, s2k.ErrBucketNotFound) {
+		t.Errorf("Must be ErrBucketNotFound: %v", e)
+	}
+
+	e = AddLogNew(d)(ctx, "l0")
+	if nil != e {
+		t.Errorf("Unable to add log: %v", e)
+	}
+
+	e = InsLogBuilder("l0")(d)(ctx, []byte("lg"))
+	if nil != e {
+		t.Errorf("Unable to insert log: %v", e)
+	}
+
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+		var r s2k.LogRead = LogReadNew(d)
+
+		_, e := r(ctx, "l1", 0, 10)
+		if !errors.Is(e, s2k.ErrBucketNotFound) {
+			t.Errorf("Must be ErrBucketNotFound: %v", e)
+		}
+
+		records, e := r(ctx, "l0", 0, 10)
+		if nil != e {
+			t.Errorf("Unable to read log: %v", e)
+		}
+		if 1 != len(records) || 1 != records[0].Id || "lg" != string(records[0].Payload) {
+			t.Errorf("Unexpected records: %v", records)
+		}
+
+		records, e = r(ctx, "l0", 1, 10)
+		if nil != e {
+			t.Errorf("Unable to read log: %v", e)
+		}
+		if 0 != len(records) {
+			t.Errorf("Unexpected records: %v", records)
+		}
+	})
+}
+
+func TestConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	d := DbNew()
+	var s s2k.Store = StoreNew(d)
+	_ = s.AddBucket(ctx, "b0")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []byte(fmt.Sprintf("k%02d", i))
+			_ = s.Set(ctx, "b0", key, key)
+			_, _ = s.Get(ctx, "b0", key)
+			_ = s.Lst(ctx, "b0", func(_ []byte) error { return nil })
+		}(i)
+	}
+	wg.Wait()
+
+	var cnt int
+	_ = s.Lst(ctx, "b0", func(_ []byte) error {
+		cnt += 1
+		return nil
+	})
+	if 16 != cnt {
+		t.Errorf("Unexpected number of keys: %v", cnt)
+	}
+}
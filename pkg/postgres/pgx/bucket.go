@@ -0,0 +1,71 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// buckets are tables whose primary key constraint is named <table>_pkc
+// LstBucket lists the buckets in the default schema only(or the buckets recorded in the registry)
+// A missing registry(no buckets added yet) is empty.
+func pgxLstBucketBuilder(qgen func() (query string, e error)) func(q pgxQuerier) s2k.LstBucket {
+	return func(q pgxQuerier) s2k.LstBucket {
+		return func(ctx context.Context, cb func(bucket string) error) error {
+			query, e := qgen()
+			if nil != e {
+				return e
+			}
+			rows, e := q.Query(ctx, query)
+			if errors.Is(ErrorConvert(e), s2k.ErrBucketNotFound) {
+				return nil
+			}
+			if nil != e {
+				return fmt.Errorf("Unable to get rows: %w", ErrorConvert(e))
+			}
+			defer rows.Close()
+
+			var read bool = false
+			for rows.Next() {
+				read = true
+				var bucket string
+				e = rows.Scan(&bucket)
+				if nil != e {
+					return fmt.Errorf("Unable to get bucket name: %w", e)
+				}
+				e = cb(bucket)
+				if nil != e {
+					return e
+				}
+			}
+			e = ErrorConvert(rows.Err())
+			if !read && errors.Is(e, s2k.ErrBucketNotFound) {
+				return nil
+			}
+			return e
+		}
+	}
+}
+
+func pgxHasBucketBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.HasBucket {
+	return func(q pgxQuerier) s2k.HasBucket {
+		return func(ctx context.Context, bucket string) (found bool, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return false, e
+			}
+			e = ErrorConvert(q.QueryRow(ctx, query).Scan(&found))
+			if errors.Is(e, s2k.ErrBucketNotFound) {
+				return false, nil
+			}
+			return found, e
+		}
+	}
+}
+
+var PgxLstBucketNew func(p *pgxpool.Pool) s2k.LstBucket = pgxDefaultMapping.LstBucket()
+var PgxHasBucketNew func(p *pgxpool.Pool) s2k.HasBucket = pgxDefaultMapping.HasBucket()
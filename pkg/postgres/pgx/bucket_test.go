@@ -0,0 +1,149 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// undefinedTableQuerier fails like a query to a missing table.
+type undefinedTableQuerier struct{ pgxQuerier }
+
+type errRow struct{ e error }
+
+func (r errRow) Scan(_ ...any) error { return r.e }
+
+func (u undefinedTableQuerier) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	return nil, &pgconn.PgError{Code: "42P01"}
+}
+
+func (u undefinedTableQuerier) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row {
+	return errRow{&pgconn.PgError{Code: "42P01"}}
+}
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var h s2k.HasBucket = pgxHasBucketBuilder(pgxDefaultMapping.gen.HasBucket)(nil)
+		_, e := h(context.Background(), "0invalid")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("missing registry", func(t *testing.T) {
+		t.Parallel()
+
+		var x PgxMapping = PgxMappingNew(pgTableValidator, s2k.BucketMapperIdentity, "test_missing_registry")
+		found, e := pgxHasBucketBuilder(x.gen.HasBucket)(undefinedTableQuerier{})(context.Background(), "b")
+		if nil != e || found {
+			t.Errorf("Must be false: %v, %v", found, e)
+		}
+		e = pgxLstBucketBuilder(x.gen.LstBucket)(undefinedTableQuerier{})(context.Background(), func(b string) error {
+			t.Errorf("Must be empty: %s", b)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Must be empty: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	tname := "test_bucket_lst"
+
+	var store s2k.Store = PgxStoreNew(p)
+	var lst s2k.LstBucket = PgxLstBucketNew(p)
+	var has s2k.HasBucket = PgxHasBucketNew(p)
+
+	_ = store.DelBucket(ctx, tname)
+	e = store.AddBucket(ctx, tname)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+
+	// non parallel
+	t.Run("has", func(t *testing.T) {
+		found, e := has(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to check bucket: %v", e)
+		}
+		if !found {
+			t.Errorf("Bucket must exist")
+		}
+		found, e = has(ctx, "test_bucket_missing")
+		if nil != e {
+			t.Errorf("Unable to check bucket: %v", e)
+		}
+		if found {
+			t.Errorf("Bucket must not exist")
+		}
+	})
+
+	t.Run("fresh registry", func(t *testing.T) {
+		registry := "test_bucket_fresh_registry"
+		_ = PgxDelBucketNew(p)(ctx, registry)
+
+		var x PgxMapping = PgxMappingNew(pgTableValidator, s2k.BucketMapperIdentity, registry)
+		found, e := x.HasBucket()(p)(ctx, tname)
+		if nil != e || found {
+			t.Errorf("Must be false: %v, %v", found, e)
+		}
+		e = x.LstBucket()(p)(ctx, func(bucket string) error {
+			t.Errorf("Must be empty: %s", bucket)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list buckets: %v", e)
+		}
+	})
+
+	t.Run("lst", func(t *testing.T) {
+		lname := "test_bucket_lst_log"
+		_ = store.DelBucket(ctx, lname)
+		t.Cleanup(func() { _ = store.DelBucket(ctx, lname) })
+		e := PgxAddLogNew(p)(ctx, lname)
+		if nil != e {
+			t.Fatalf("Unable to create log: %v", e)
+		}
+
+		found := false
+		e = lst(ctx, func(bucket string) error {
+			found = found || tname == bucket
+			if lname == bucket {
+				t.Errorf("Log must not be listed")
+			}
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list buckets: %v", e)
+		}
+		if !found {
+			t.Errorf("Bucket must be listed")
+		}
+		found, e = has(ctx, lname)
+		if nil != e || found {
+			t.Errorf("Log must not be a bucket: %v, %v", found, e)
+		}
+	})
+}
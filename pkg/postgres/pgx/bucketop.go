@@ -0,0 +1,52 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+type pairQueryGenerator func(src, dst string) (query string, e error)
+
+// pgxPairExecBuilder executes the multi statement query without arguments(simple protocol).
+func pgxPairExecBuilder(qgen pairQueryGenerator) func(q pgxQuerier) func(ctx context.Context, src, dst string) error {
+	return func(q pgxQuerier) func(ctx context.Context, src, dst string) error {
+		return func(ctx context.Context, src, dst string) error {
+			query, e := qgen(src, dst)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+func pgxRenameBucketBuilder(qgen pairQueryGenerator) func(q pgxQuerier) s2k.RenameBucket {
+	return func(q pgxQuerier) s2k.RenameBucket { return pgxPairExecBuilder(qgen)(q) }
+}
+
+func pgxCopyBucketBuilder(qgen pairQueryGenerator) func(q pgxQuerier) s2k.CopyBucket {
+	return func(q pgxQuerier) s2k.CopyBucket { return pgxPairExecBuilder(qgen)(q) }
+}
+
+func pgxTruncateBucketBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.TruncateBucket {
+	return func(q pgxQuerier) s2k.TruncateBucket {
+		return func(ctx context.Context, bucket string) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+var PgxRenameBucketNew func(p *pgxpool.Pool) s2k.RenameBucket = pgxDefaultMapping.RenameBucket()
+
+// PgxCopyBucketNew copies buckets with the index on expires_at(partitioned buckets are rejected).
+var PgxCopyBucketNew func(p *pgxpool.Pool) s2k.CopyBucket = pgxDefaultMapping.CopyBucket()
+var PgxTruncateBucketNew func(p *pgxpool.Pool) s2k.TruncateBucket = pgxDefaultMapping.TruncateBucket()
@@ -0,0 +1,156 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestBucketOp(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var r s2k.RenameBucket = pgxRenameBucketBuilder(pgxDefaultMapping.gen.RenameBucket)(nil)
+		e := r(context.Background(), "src", "0invalid")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+
+		var c s2k.CopyBucket = pgxCopyBucketBuilder(pgxDefaultMapping.gen.CopyBucket)(nil)
+		e = c(context.Background(), "0invalid", "dst")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	live := "test_bucketop_live"
+	fresh := "test_bucketop_fresh"
+	old := "test_bucketop_old"
+
+	var store s2k.Store = PgxStoreNew(p)
+	var rename s2k.RenameBucket = PgxRenameBucketNew(p)
+	var cp s2k.CopyBucket = PgxCopyBucketNew(p)
+	var truncate s2k.TruncateBucket = PgxTruncateBucketNew(p)
+	var has s2k.HasBucket = PgxHasBucketNew(p)
+
+	for _, b := range []string{live, fresh, old} {
+		_ = store.DelBucket(ctx, b)
+	}
+	e = store.AddBucket(ctx, live)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+	_ = store.Set(ctx, live, []byte("k"), []byte("v0"))
+
+	// non parallel
+	t.Run("copy", func(t *testing.T) {
+		e := cp(ctx, live, fresh)
+		if nil != e {
+			t.Fatalf("Unable to copy: %v", e)
+		}
+		got, e := store.Get(ctx, fresh, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v0"))
+		e = store.Set(ctx, fresh, []byte("k"), []byte("v1"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		e := rename(ctx, live, old)
+		if nil != e {
+			t.Fatalf("Unable to rename: %v", e)
+		}
+		e = rename(ctx, fresh, live)
+		if nil != e {
+			t.Fatalf("Unable to rename: %v", e)
+		}
+		found, _ := has(ctx, live)
+		if !found {
+			t.Errorf("Renamed bucket must keep the pkc convention")
+		}
+		got, e := store.Get(ctx, live, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v1"))
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		e := truncate(ctx, old)
+		if nil != e {
+			t.Errorf("Unable to truncate: %v", e)
+		}
+		_, e = store.Get(ctx, old, []byte("k"))
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("copy ttl", func(t *testing.T) {
+		src := "test_bucketop_ttl"
+		dst := "test_bucketop_ttl_copy"
+		for _, b := range []string{src, dst} {
+			b := b
+			_ = store.DelBucket(ctx, b)
+			t.Cleanup(func() { _ = store.DelBucket(ctx, b) })
+		}
+		e := PgxAddTtlBucketNew(p)(ctx, src)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+		e = cp(ctx, src, dst)
+		if nil != e {
+			t.Fatalf("Unable to copy: %v", e)
+		}
+		var found bool
+		e = p.QueryRow(ctx, "SELECT TO_REGCLASS('test_bucketop_ttl_copy_exp') IS NOT NULL").Scan(&found)
+		if nil != e || !found {
+			t.Errorf("Must recreate the index on expires_at: %v", e)
+		}
+	})
+
+	t.Run("copy partitioned", func(t *testing.T) {
+		src := "test_bucketop_part"
+		dst := "test_bucketop_part_copy"
+		for _, b := range []string{src, dst} {
+			b := b
+			_ = store.DelBucket(ctx, b)
+			t.Cleanup(func() { _ = store.DelBucket(ctx, b) })
+		}
+		e := PgxAddPartitionedBucketNew(2)(p)(ctx, src)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+		e = cp(ctx, src, dst)
+		if nil == e {
+			t.Errorf("Must reject partitioned src")
+		}
+		found, _ := has(ctx, dst)
+		if found {
+			t.Errorf("Must not create dst")
+		}
+	})
+}
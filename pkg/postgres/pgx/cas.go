@@ -0,0 +1,76 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// noRows2conflict converts "no row updated" into s2k.ErrConflict.
+func noRows2conflict(e error) error {
+	return s2k.ErrorReplaceNew(s2k.ErrNotFound, s2k.ErrConflict, ErrorConvert(e))
+}
+
+func pgxCompareAndSetBuilder(update, insert QueryGenerator) func(q pgxQuerier) s2k.CompareAndSet {
+	return func(q pgxQuerier) s2k.CompareAndSet {
+		return func(ctx context.Context, bucket string, key, expected, val []byte) error {
+			var query string
+			var e error
+			var args []any
+			if nil == expected {
+				query, e = insert(bucket)
+				args = []any{key, val}
+			} else {
+				query, e = update(bucket)
+				args = []any{key, expected, val}
+			}
+			if nil != e {
+				return e
+			}
+			var updated []byte
+			e = q.QueryRow(ctx, query, args...).Scan(&updated)
+			return noRows2conflict(e)
+		}
+	}
+}
+
+func pgxGetVersionBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.GetVersion {
+	return func(q pgxQuerier) s2k.GetVersion {
+		return func(ctx context.Context, bucket string, key []byte) (val []byte, version int64, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return nil, 0, e
+			}
+			e = q.QueryRow(ctx, query, key).Scan(&val, &version)
+			return val, version, ErrorConvert(e)
+		}
+	}
+}
+
+func pgxCompareAndSetVersionBuilder(update, insert QueryGenerator) func(q pgxQuerier) s2k.CompareAndSetVersion {
+	return func(q pgxQuerier) s2k.CompareAndSetVersion {
+		return func(ctx context.Context, bucket string, key []byte, version int64, val []byte) (newVersion int64, e error) {
+			var query string
+			var args []any
+			if 0 == version {
+				query, e = insert(bucket)
+				args = []any{key, val}
+			} else {
+				query, e = update(bucket)
+				args = []any{key, version, val}
+			}
+			if nil != e {
+				return 0, e
+			}
+			e = q.QueryRow(ctx, query, args...).Scan(&newVersion)
+			return newVersion, noRows2conflict(e)
+		}
+	}
+}
+
+var PgxCompareAndSetNew func(p *pgxpool.Pool) s2k.CompareAndSet = pgxDefaultMapping.CompareAndSet()
+var PgxGetVersionNew func(p *pgxpool.Pool) s2k.GetVersion = pgxDefaultMapping.GetVersion()
+var PgxCompareAndSetVersionNew func(p *pgxpool.Pool) s2k.CompareAndSetVersion = pgxDefaultMapping.CompareAndSetVersion()
+var PgxAddVersionBucketNew func(p *pgxpool.Pool) s2k.AddBucket = pgxDefaultMapping.AddVersionBucket()
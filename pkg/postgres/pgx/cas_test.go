@@ -0,0 +1,152 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestCompareAndSet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Parallel()
+		e := noRows2conflict(pgx.ErrNoRows)
+		if !errors.Is(e, s2k.ErrConflict) || !errors.Is(e, pgx.ErrNoRows) {
+			t.Errorf("Must be ErrConflict(pgx.ErrNoRows): %v", e)
+		}
+		if errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	key := []byte("k")
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+
+		var cas s2k.CompareAndSet = PgxCompareAndSetNew(p)
+		var store s2k.Store = PgxStoreNew(p)
+		tname := "test_cas_value"
+
+		_ = store.DelBucket(ctx, tname)
+		e := store.AddBucket(ctx, tname)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+
+		// non parallel
+		t.Run("insert", func(t *testing.T) {
+			e := cas(ctx, tname, key, nil, []byte("v0"))
+			if nil != e {
+				t.Errorf("Unable to insert: %v", e)
+			}
+			e = cas(ctx, tname, key, nil, []byte("v0"))
+			if !errors.Is(e, s2k.ErrConflict) {
+				t.Errorf("Must be ErrConflict: %v", e)
+			}
+		})
+
+		t.Run("update", func(t *testing.T) {
+			e := cas(ctx, tname, key, []byte("v0"), []byte("v1"))
+			if nil != e {
+				t.Errorf("Unable to update: %v", e)
+			}
+			e = cas(ctx, tname, key, []byte("v0"), []byte("v2"))
+			if !errors.Is(e, s2k.ErrConflict) || errors.Is(e, s2k.ErrNotFound) {
+				t.Errorf("Must be ErrConflict only: %v", e)
+			}
+			got, _ := store.Get(ctx, tname, key)
+			checkBytes(t, got, []byte("v1"))
+		})
+	})
+
+	t.Run("version", func(t *testing.T) {
+		t.Parallel()
+
+		var cas s2k.CompareAndSetVersion = PgxCompareAndSetVersionNew(p)
+		var gv s2k.GetVersion = PgxGetVersionNew(p)
+		var ab s2k.AddBucket = PgxAddVersionBucketNew(p)
+		var db s2k.DelBucket = PgxDelBucketNew(p)
+		tname := "test_cas_version"
+
+		_ = db(ctx, tname)
+		e := ab(ctx, tname)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+
+		// non parallel
+		t.Run("insert", func(t *testing.T) {
+			ver, e := cas(ctx, tname, key, 0, []byte("v0"))
+			if nil != e {
+				t.Errorf("Unable to insert: %v", e)
+			}
+			if 1 != ver {
+				t.Errorf("Unexpected version: %v", ver)
+			}
+			_, e = cas(ctx, tname, key, 0, []byte("v0"))
+			if !errors.Is(e, s2k.ErrConflict) {
+				t.Errorf("Must be ErrConflict: %v", e)
+			}
+		})
+
+		t.Run("update", func(t *testing.T) {
+			ver, e := cas(ctx, tname, key, 1, []byte("v1"))
+			if nil != e {
+				t.Errorf("Unable to update: %v", e)
+			}
+			if 2 != ver {
+				t.Errorf("Unexpected version: %v", ver)
+			}
+			_, e = cas(ctx, tname, key, 1, []byte("v2"))
+			if !errors.Is(e, s2k.ErrConflict) {
+				t.Errorf("Must be ErrConflict: %v", e)
+			}
+		})
+
+		t.Run("get", func(t *testing.T) {
+			val, ver, e := gv(ctx, tname, key)
+			if nil != e {
+				t.Errorf("Unable to get: %v", e)
+			}
+			checkBytes(t, val, []byte("v1"))
+			if 2 != ver {
+				t.Errorf("Unexpected version: %v", ver)
+			}
+		})
+
+		t.Run("set keeps version", func(t *testing.T) {
+			e := PgxSetNew(p)(ctx, tname, key, []byte("v2"))
+			if nil != e {
+				t.Errorf("Unable to set: %v", e)
+			}
+			val, ver, e := gv(ctx, tname, key)
+			if nil != e {
+				t.Errorf("Unable to get: %v", e)
+			}
+			checkBytes(t, val, []byte("v2"))
+			if 2 != ver {
+				t.Errorf("Unexpected version: %v", ver)
+			}
+		})
+	})
+}
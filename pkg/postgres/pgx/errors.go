@@ -0,0 +1,20 @@
+package pgx2kv
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// ErrorConvert converts native errors(pgx.ErrNoRows, *pgconn.PgError) into the sentinel errors of s2k.
+func ErrorConvert(e error) error {
+	if nil == e {
+		return nil
+	}
+	if errors.Is(e, pgx.ErrNoRows) {
+		return s2k.ErrorConvertNew(s2k.ErrNotFound, e)
+	}
+	return s2k.ErrorFromSqlState(e)
+}
@@ -0,0 +1,41 @@
+package pgx2kv
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestErrorConvert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		if nil != ErrorConvert(nil) {
+			t.Errorf("Must be nil")
+		}
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		t.Parallel()
+		e := ErrorConvert(fmt.Errorf("wrapped: %w", pgx.ErrNoRows))
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be ErrNotFound: %v", e)
+		}
+		if !errors.Is(e, pgx.ErrNoRows) {
+			t.Errorf("Must keep pgx.ErrNoRows: %v", e)
+		}
+	})
+
+	t.Run("invalid table name", func(t *testing.T) {
+		t.Parallel()
+		e := pgTableValidator("0table")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+}
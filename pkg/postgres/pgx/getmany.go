@@ -0,0 +1,54 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+var pgGetQueryGenerator QueryGenerator = pgxDefaultMapping.gen.Get
+
+func pgxGetManyBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.GetMany {
+	return func(q pgxQuerier) s2k.GetMany {
+		return func(ctx context.Context, bucket string, keys [][]byte) (found []s2k.Pair, missing [][]byte, e error) {
+			if 0 == len(keys) {
+				return nil, nil, nil
+			}
+
+			query, e := qgen(bucket)
+			if nil != e {
+				return nil, nil, e
+			}
+
+			var pb pgx.Batch
+			for _, key := range keys {
+				pb.Queue(query, key)
+			}
+
+			results := q.SendBatch(ctx, &pb)
+			defer results.Close()
+
+			got := make(map[string][]byte, len(keys))
+			for _, key := range keys {
+				var val []byte
+				e := results.QueryRow().Scan(&val)
+				if errors.Is(e, pgx.ErrNoRows) {
+					continue
+				}
+				if nil != e {
+					return nil, nil, ErrorConvert(e)
+				}
+				got[string(key)] = val
+			}
+
+			found, missing = s2k.SplitFound(keys, got)
+			return
+		}
+	}
+}
+
+var PgxGetManyNew func(p *pgxpool.Pool) s2k.GetMany = pgxDefaultMapping.GetMany()
@@ -0,0 +1,76 @@
+package pgx2kv
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestGetMany(t *testing.T) {
+	t.Parallel()
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var gm s2k.GetMany = PgxGetManyNew(p)
+	var ab s2k.AddBucket = PgxAddBucketNew(p)
+	var sm s2k.SetMany = PgxBulkSetNew(p)
+
+	t.Run("invalid table name", func(t *testing.T) {
+		t.Parallel()
+		_, _, e := gm(context.Background(), "0table", [][]byte{[]byte("k")})
+		if nil == e {
+			t.Errorf("Must reject invalid table name")
+		}
+	})
+
+	// non parallel
+	t.Run("ordered", func(t *testing.T) {
+		tname := "test_get_many"
+
+		e := ab(context.Background(), tname)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+
+		e = sm(context.Background(), tname, []s2k.Pair{
+			{Key: []byte("k0"), Val: []byte("v0")},
+			{Key: []byte("k2"), Val: []byte("v2")},
+		})
+		if nil != e {
+			t.Fatalf("Unable to set: %v", e)
+		}
+
+		found, missing, e := gm(context.Background(), tname, [][]byte{
+			[]byte("k0"),
+			[]byte("k1"),
+			[]byte("k2"),
+		})
+		if nil != e {
+			t.Fatalf("Unable to get: %v", e)
+		}
+
+		if 2 != len(found) {
+			t.Fatalf("Unexpected number of found pairs: %v", len(found))
+		}
+		checkBytes(t, found[0].Val, []byte("v0"))
+		checkBytes(t, found[1].Val, []byte("v2"))
+
+		if 1 != len(missing) {
+			t.Fatalf("Unexpected number of missing keys: %v", len(missing))
+		}
+		checkBytes(t, missing[0], []byte("k1"))
+	})
+}
@@ -0,0 +1,30 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// counters are stored as 8 byte big endian integers(see s2k.CounterEncode)
+func pgxIncrBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Incr {
+	return func(q pgxQuerier) s2k.Incr {
+		return func(ctx context.Context, bucket string, key []byte, delta int64) (i int64, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return 0, e
+			}
+			e = q.QueryRow(ctx, query, key, delta).Scan(&i)
+			return i, noRows2invalid(e)
+		}
+	}
+}
+
+// noRows2invalid converts "no row updated"(not a counter) into s2k.ErrInvalidValue.
+func noRows2invalid(e error) error {
+	return s2k.ErrorReplaceNew(s2k.ErrNotFound, s2k.ErrInvalidValue, ErrorConvert(e))
+}
+
+var PgxIncrNew func(p *pgxpool.Pool) s2k.Incr = pgxDefaultMapping.Incr()
@@ -0,0 +1,98 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestIncr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not a counter", func(t *testing.T) {
+		t.Parallel()
+		e := noRows2invalid(pgx.ErrNoRows)
+		if !errors.Is(e, s2k.ErrInvalidValue) || !errors.Is(e, pgx.ErrNoRows) {
+			t.Errorf("Must be ErrInvalidValue(pgx.ErrNoRows): %v", e)
+		}
+		if errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must not be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var i s2k.Incr = pgxIncrBuilder(pgxDefaultMapping.gen.Incr)(nil)
+		_, e := i(context.Background(), "0invalid", nil, 1)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	tname := "test_incr"
+
+	var store s2k.Store = PgxStoreNew(p)
+	var incr s2k.Incr = PgxIncrNew(p)
+
+	_ = store.DelBucket(ctx, tname)
+	e = store.AddBucket(ctx, tname)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+
+	// non parallel
+	t.Run("insert", func(t *testing.T) {
+		i, e := incr(ctx, tname, []byte("c"), 3)
+		if nil != e {
+			t.Errorf("Unable to incr: %v", e)
+		}
+		if 3 != i {
+			t.Errorf("Unexpected value: %v", i)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		i, e := incr(ctx, tname, []byte("c"), -5)
+		if nil != e {
+			t.Errorf("Unable to incr: %v", e)
+		}
+		if -2 != i {
+			t.Errorf("Unexpected value: %v", i)
+		}
+		v, e := store.Get(ctx, tname, []byte("c"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, v, s2k.CounterEncode(-2))
+	})
+
+	t.Run("not a counter", func(t *testing.T) {
+		e := store.Set(ctx, tname, []byte("s"), []byte("str"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		_, e = incr(ctx, tname, []byte("s"), 1)
+		if !errors.Is(e, s2k.ErrInvalidValue) || errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be ErrInvalidValue only: %v", e)
+		}
+	})
+}
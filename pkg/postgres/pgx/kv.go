@@ -0,0 +1,131 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// pgxQuerier is implemented by *pgxpool.Pool and pgx.Tx
+type pgxQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+func pool2querier[T any](f func(q pgxQuerier) T) func(p *pgxpool.Pool) T {
+	return func(p *pgxpool.Pool) T { return f(p) }
+}
+
+var pgLstQueryGenerator QueryGenerator = pgxDefaultMapping.gen.Lst
+var pgAddQueryGenerator QueryGenerator = pgxDefaultMapping.gen.Add
+var pgDelQueryGenerator QueryGenerator = pgxDefaultMapping.gen.Del
+var pgSetQueryGenerator QueryGenerator = pgxDefaultMapping.gen.Set
+
+func pgxGetBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Get {
+	return func(q pgxQuerier) s2k.Get {
+		return func(ctx context.Context, bucket string, key []byte) (val []byte, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return nil, e
+			}
+			e = q.QueryRow(ctx, query, key).Scan(&val)
+			return val, ErrorConvert(e)
+		}
+	}
+}
+
+func pgxLstBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Lst {
+	return func(q pgxQuerier) s2k.Lst {
+		var qcb s2k.QueryCb = pgxQueryCbNew(q)
+		return func(ctx context.Context, bucket string, cb func(key []byte) error) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			return qcb(
+				ctx,
+				func(row s2k.Record) error {
+					var key []byte
+					e := row.Scan(&key)
+					if nil != e {
+						return e
+					}
+					return cb(key)
+				},
+				query,
+			)
+		}
+	}
+}
+
+func pgxDelBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Del {
+	return func(q pgxQuerier) s2k.Del {
+		return func(ctx context.Context, bucket string, key []byte) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query, key)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+// pgxWriteBuilder creates Add or Set which differ only in the query.
+func pgxWriteBuilder(qgen QueryGenerator) func(q pgxQuerier) func(ctx context.Context, bucket string, key, val []byte) error {
+	return func(q pgxQuerier) func(ctx context.Context, bucket string, key, val []byte) error {
+		return func(ctx context.Context, bucket string, key, val []byte) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query, key, val)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+func pgxAddBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Add {
+	return func(q pgxQuerier) s2k.Add { return pgxWriteBuilder(qgen)(q) }
+}
+
+func pgxSetBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Set {
+	return func(q pgxQuerier) s2k.Set { return pgxWriteBuilder(qgen)(q) }
+}
+
+var PgxGetNew func(p *pgxpool.Pool) s2k.Get = pool2querier(pgxGetBuilder(pgGetQueryGenerator))
+var PgxLstNew func(p *pgxpool.Pool) s2k.Lst = pool2querier(pgxLstBuilder(pgLstQueryGenerator))
+var PgxDelNew func(p *pgxpool.Pool) s2k.Del = pool2querier(pgxDelBuilder(pgDelQueryGenerator))
+var PgxAddNew func(p *pgxpool.Pool) s2k.Add = pool2querier(pgxAddBuilder(pgAddQueryGenerator))
+var PgxSetNew func(p *pgxpool.Pool) s2k.Set = pool2querier(pgxSetBuilder(pgSetQueryGenerator))
+
+// pgxStoreValidatorBuilder creates a Store which accepts buckets checked by v(names are always quoted).
+func pgxStoreValidatorBuilder(v tableValidator) func(q pgxQuerier) s2k.Store {
+	return PgxMappingNew(v, s2k.BucketMapperIdentity, "").storeBuilder
+}
+
+var pgxStoreBuilder func(q pgxQuerier) s2k.Store = pgxDefaultMapping.storeBuilder
+
+var PgxStoreNew func(p *pgxpool.Pool) s2k.Store = pool2querier(pgxStoreBuilder)
+
+func PgxTxStoreNew(t pgx.Tx) s2k.Store { return pgxStoreBuilder(t) }
+
+// PgxStoreValidatorNew creates a Store builder which uses the custom bucket validator.
+func PgxStoreValidatorNew(v func(bucket string) error) func(p *pgxpool.Pool) s2k.Store {
+	return pool2querier(pgxStoreValidatorBuilder(v))
+}
+
+func PgxWithTxNew(p *pgxpool.Pool) s2k.WithTx {
+	return func(ctx context.Context, f func(tx s2k.Store) error) error {
+		// BeginFunc rolls back on error or panic
+		return poolExec(ctx, p, func(t pgx.Tx) error {
+			return f(PgxTxStoreNew(t))
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
+)
+
+func TestKvInvalidBucket(t *testing.T) {
+	t.Parallel()
+
+	var p *pgxpool.Pool // must not be used
+	ctx := context.Background()
+
+	pat := []struct {
+		f func() error
+		n string
+	}{
+		{f: func() error { _, e := PgxGetNew(p)(ctx, "0table", nil); return e }, n: "Get"},
+		{f: func() error { return PgxLstNew(p)(ctx, "0table", nil) }, n: "Lst"},
+		{f: func() error { return PgxDelNew(p)(ctx, "0table", nil) }, n: "Del"},
+		{f: func() error { return PgxAddNew(p)(ctx, "0table", nil, nil) }, n: "Add"},
+		{f: func() error { return PgxSetNew(p)(ctx, "0table", nil, nil) }, n: "Set"},
+		{f: func() error { return PgxStoreNew(p).AddBucket(ctx, "0table") }, n: "Store"},
+	}
+
+	for _, pt := range pat {
+		pt := pt
+		t.Run(pt.n, func(t *testing.T) {
+			t.Parallel()
+			e := pt.f()
+			if !errors.Is(e, s2k.ErrInvalidBucket) {
+				t.Errorf("Must reject invalid table name: %v", e)
+			}
+		})
+	}
+}
+
+func TestKv(t *testing.T) {
+	t.Parallel()
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var get s2k.Get = PgxGetNew(p)
+	var lst s2k.Lst = PgxLstNew(p)
+	var del s2k.Del = PgxDelNew(p)
+	var add s2k.Add = PgxAddNew(p)
+	var set s2k.Set = PgxSetNew(p)
+	var ab s2k.AddBucket = PgxAddBucketNew(p)
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+
+	ctx := context.Background()
+	tname := "test_kv_pgx"
+	key := []byte("k")
+
+	// non parallel
+	t.Run("add bucket", func(t *testing.T) {
+		e := db(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to drop table: %v", e)
+		}
+		e = ab(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to create table: %v", e)
+		}
+	})
+
+	t.Run("get(before add)", func(t *testing.T) {
+		_, e := get(ctx, tname, key)
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("add", func(t *testing.T) {
+		e := add(ctx, tname, key, []byte("v0"))
+		if nil != e {
+			t.Errorf("Unable to add: %v", e)
+		}
+	})
+
+	t.Run("add(dup)", func(t *testing.T) {
+		e := add(ctx, tname, key, []byte("v0"))
+		if !errors.Is(e, s2k.ErrDuplicateKey) {
+			t.Errorf("Must be ErrDuplicateKey: %v", e)
+		}
+	})
+
+	t.Run("set", func(t *testing.T) {
+		e := set(ctx, tname, key, []byte("v1"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, e := get(ctx, tname, key)
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v1"))
+	})
+
+	t.Run("lst", func(t *testing.T) {
+		var keys [][]byte
+		e := lst(ctx, tname, func(k []byte) error {
+			keys = append(keys, k)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list: %v", e)
+		}
+		if 1 != len(keys) {
+			t.Fatalf("Unexpected number of keys: %v", len(keys))
+		}
+		checkBytes(t, keys[0], key)
+	})
+
+	t.Run("del", func(t *testing.T) {
+		e := del(ctx, tname, key)
+		if nil != e {
+			t.Errorf("Unable to delete: %v", e)
+		}
+		_, e = get(ctx, tname, key)
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Must be ErrNotFound: %v", e)
+		}
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		_, e := get(ctx, "test_kv_pgx_missing", key)
+		if !errors.Is(e, s2k.ErrBucketNotFound) {
+			t.Errorf("Must be ErrBucketNotFound: %v", e)
+		}
+	})
+}
+
+func TestPgxStoreValidatorNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("quoted", func(t *testing.T) {
+		t.Parallel()
+		pat := []struct {
+			bucket   string
+			expected string
+		}{
+			{bucket: "Team-A.バケット", expected: `"Team-A"."バケット"`},
+			{bucket: `x"; DROP TABLE y; --`, expected: `"x""; DROP TABLE y; --"`},
+		}
+		for _, p := range pat {
+			got := pg.BucketTable(p.bucket)
+			if p.expected != got {
+				t.Errorf("Unexpected identifier: %s", got)
+			}
+		}
+		if `'"it''s"'` != bucketData("it's")["tableLit"] {
+			t.Errorf("Unexpected literal: %s", bucketData("it's")["tableLit"])
+		}
+	})
+
+	t.Run("same schema as postgres generator", func(t *testing.T) {
+		t.Parallel()
+		q, e := pgxDefaultMapping.gen.AddBucket("t0")
+		if nil != e {
+			t.Fatalf("Unable to create query: %v", e)
+		}
+		expected, _ := pg.QueryGeneratorNew(pg.Validator(pgTableValidator), pg.Validator(pgNamespaceValidator)).AddBucket("t0")
+		if expected != q || !strings.Contains(q, "val BYTEA NOT NULL") {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+		var store s2k.Store = pgxStoreValidatorBuilder(func(bucket string) error {
+			if "Allowed" == bucket {
+				return nil
+			}
+			return s2k.ErrInvalidBucket
+		})(nil)
+		_, e := store.Get(context.Background(), "t0", nil)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		t.Parallel()
+		accept := func(_ string) error { return nil }
+		long := strings.Repeat("t", 60)
+		var store s2k.Store = pgxStoreValidatorBuilder(accept)(nil)
+		_, e := store.Get(context.Background(), long, nil)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long name: %v", e)
+		}
+		e = store.AddBucket(context.Background(), strings.Repeat("n", 64)+".t")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long namespace: %v", e)
+		}
+		_, e = PgxMappingNew(accept, s2k.BucketMapperIdentity, "").gen.Get(long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long mapped name: %v", e)
+		}
+		_, e = PgxMappingNew(accept, s2k.BucketMapperIdentity, "").generator(pgLogReadRawGenerator)(long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long log name: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var store s2k.Store = PgxStoreValidatorNew(func(_ string) error { return nil })(p)
+	ctx := context.Background()
+	bucket := "Test-Bucket-バケット"
+
+	_ = store.DelBucket(ctx, bucket)
+
+	// non parallel
+	t.Run("add", func(t *testing.T) {
+		e := store.AddBucket(ctx, bucket)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+	})
+
+	t.Run("set/get", func(t *testing.T) {
+		e := store.Set(ctx, bucket, []byte("k"), []byte("v"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, e := store.Get(ctx, bucket, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v"))
+	})
+
+	t.Run("del", func(t *testing.T) {
+		e := store.DelBucket(ctx, bucket)
+		if nil != e {
+			t.Errorf("Unable to remove bucket: %v", e)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package pgx2kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+var pgLogReadRawGenerator QueryGenerator = strQueryGeneratorNewMust(`
+	SELECT id, lg FROM {{.tableName}}
+	WHERE $1 < id
+	ORDER BY id
+	LIMIT $2
+`)
+
+func pgxLogReadBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.LogRead {
+	return func(q pgxQuerier) s2k.LogRead {
+		var qcb s2k.QueryCb = pgxQueryCbNew(q)
+		return func(ctx context.Context, bucket string, afterId int64, limit int64) ([]s2k.LogRecord, error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return nil, e
+			}
+			var records []s2k.LogRecord
+			e = qcb(
+				ctx,
+				func(row s2k.Record) error {
+					var rec s2k.LogRecord
+					e := row.Scan(&rec.Id, &rec.Payload)
+					if nil != e {
+						return e
+					}
+					records = append(records, rec)
+					return nil
+				},
+				query,
+				afterId,
+				limit,
+			)
+			return records, e
+		}
+	}
+}
+
+// PgxLogReadNew reads logs after the id.
+// Logs appended by this package never appear after larger ids(appends to a log are serialized until commit).
+var PgxLogReadNew func(p *pgxpool.Pool) s2k.LogRead = pgxDefaultMapping.LogRead()
+
+// PgxLogTailNew creates LogTail which polls the log every interval when caught up.
+func PgxLogTailNew(interval time.Duration, limit int64) func(p *pgxpool.Pool) s2k.LogTail {
+	return pgxDefaultMapping.LogTail(interval, limit)
+}
+
+var pgLogInsertReturningRawGenerator QueryGenerator = strQueryGeneratorNewMust(pgLogLockQuery + `
+	INSERT INTO {{.tableName}} (lg)
+	SELECT $1::BYTEA FROM locked
+	RETURNING id
+`)
+
+func pgxLogInsManyTranBuilderNew(query builtQuery) func(t pgx.Tx) s2k.InsLogMany {
+	return func(t pgx.Tx) s2k.InsLogMany {
+		return func(ctx context.Context, logs s2k.Iter[[]byte]) (ids []int64, e error) {
+			if nil != query.e {
+				return nil, query.e
+			}
+
+			var pb pgx.Batch
+			for o := logs(); o.HasValue(); o = logs() {
+				pb.Queue(query.query, o.Value())
+			}
+
+			l := pb.Len()
+			if 0 == l {
+				return nil, nil
+			}
+			results := t.SendBatch(ctx, &pb)
+			defer results.Close()
+
+			ids = make([]int64, l)
+			for i := 0; i < l; i++ {
+				e = results.QueryRow().Scan(&ids[i])
+				if nil != e {
+					return nil, ErrorConvert(e)
+				}
+			}
+			return ids, nil
+		}
+	}
+}
+
+func pgxLogInsManyBuilder(tx2ins func(pgx.Tx) s2k.InsLogMany) func(*pgxpool.Pool) s2k.InsLogMany {
+	return func(p *pgxpool.Pool) s2k.InsLogMany {
+		return func(ctx context.Context, logs s2k.Iter[[]byte]) (ids []int64, e error) {
+			e = poolExec(ctx, p, func(tx pgx.Tx) error {
+				ids, e = tx2ins(tx)(ctx, logs)
+				return e
+			})
+			if nil != e {
+				return nil, e
+			}
+			return ids, nil
+		}
+	}
+}
+
+var pgxLogInsManyNew func(query builtQuery) func(*pgxpool.Pool) s2k.InsLogMany = s2k.Compose(
+	pgxLogInsManyTranBuilderNew,
+	pgxLogInsManyBuilder,
+)
+
+// PgxLogInsManyBuilder creates InsLogMany which inserts logs by a batch in a transaction.
+var PgxLogInsManyBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.InsLogMany = pgxDefaultMapping.LogInsMany
@@ -0,0 +1,218 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestLogRead(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var r s2k.LogRead = pgxLogReadBuilder(pgxDefaultMapping.generator(pgLogReadRawGenerator))(nil)
+		_, e := r(context.Background(), "0invalid", 0, 1)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("InsLogMany invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var ins s2k.InsLogMany = pgxLogInsManyTranBuilderNew(pgxDefaultMapping.generator(pgLogInsertReturningRawGenerator).build("0invalid"))(nil)
+		_, e := ins(context.Background(), s2k.IterFromArray([][]byte{[]byte("lg")}))
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("serialized appends", func(t *testing.T) {
+		t.Parallel()
+
+		for _, g := range []QueryGenerator{
+			pgLogInsertRawGenerator,
+			pgLogInsertReturningRawGenerator,
+			pgLogInsertNotifyRawGenerator,
+		} {
+			q, e := g("l0")
+			if nil != e {
+				t.Errorf("Unexpected error: %v", e)
+			}
+			if !strings.Contains(q, `PG_ADVISORY_XACT_LOCK(7549547, ('"l0"'::REGCLASS::OID::BIGINT - 2147483648)::INTEGER)`) {
+				t.Errorf("Must lock the log: %s", q)
+			}
+		}
+	})
+
+	t.Run("InsLogMany empty", func(t *testing.T) {
+		t.Parallel()
+
+		var ins s2k.InsLogMany = pgxLogInsManyTranBuilderNew(pgxDefaultMapping.generator(pgLogInsertReturningRawGenerator).build("l0"))(nil)
+		ids, e := ins(context.Background(), s2k.IterEmptyNew[[]byte]())
+		if nil != e || 0 != len(ids) {
+			t.Errorf("Should be nop: %v, %v", ids, e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	lname := "test_log_read"
+
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+	var al s2k.AddLog = PgxAddLogNew(p)
+	var ins s2k.InsLog = PgxLogInsBuilder(lname)(p)
+	var r s2k.LogRead = PgxLogReadNew(p)
+
+	_ = db(ctx, lname)
+	e = al(ctx, lname)
+	if nil != e {
+		t.Fatalf("Unable to create log: %v", e)
+	}
+	t.Cleanup(func() { _ = db(ctx, lname) })
+
+	for i := 0; i < 5; i++ {
+		e := ins(ctx, []byte(fmt.Sprintf("lg%d", i)))
+		if nil != e {
+			t.Fatalf("Unable to insert log: %v", e)
+		}
+	}
+
+	// non parallel
+	t.Run("read", func(t *testing.T) {
+		records, e := r(ctx, lname, 0, 3)
+		if nil != e {
+			t.Fatalf("Unable to read log: %v", e)
+		}
+		if 3 != len(records) {
+			t.Fatalf("Unexpected records: %v", records)
+		}
+		checkBytes(t, records[0].Payload, []byte("lg0"))
+
+		rest, e := r(ctx, lname, records[2].Id, 3)
+		if nil != e {
+			t.Fatalf("Unable to read log: %v", e)
+		}
+		if 2 != len(rest) {
+			t.Fatalf("Unexpected records: %v", rest)
+		}
+		checkBytes(t, rest[1].Payload, []byte("lg4"))
+	})
+
+	t.Run("InsLogMany", func(t *testing.T) {
+		var ins s2k.InsLogMany = PgxLogInsManyBuilder(lname)(p)
+		ids, e := ins(ctx, s2k.IterFromArray([][]byte{
+			[]byte("lg5"),
+			[]byte("lg6"),
+		}))
+		if nil != e {
+			t.Fatalf("Unable to insert logs: %v", e)
+		}
+		if 2 != len(ids) || ids[1] <= ids[0] {
+			t.Fatalf("Unexpected ids: %v", ids)
+		}
+
+		records, e := r(ctx, lname, ids[0]-1, 10)
+		if nil != e {
+			t.Fatalf("Unable to read log: %v", e)
+		}
+		if 2 != len(records) || ids[1] != records[1].Id {
+			t.Fatalf("Unexpected records: %v", records)
+		}
+		checkBytes(t, records[1].Payload, []byte("lg6"))
+	})
+
+	t.Run("tail", func(t *testing.T) {
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		var payloads []string
+		e := PgxLogTailNew(10*time.Millisecond, 2)(p)(c, lname, 0, func(rec s2k.LogRecord) error {
+			payloads = append(payloads, string(rec.Payload))
+			if 7 == len(payloads) {
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(e, context.Canceled) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 7 != len(payloads) || "lg6" != payloads[6] {
+			t.Errorf("Unexpected payloads: %v", payloads)
+		}
+	})
+
+	t.Run("concurrent transactions", func(t *testing.T) {
+		query, e := pgxDefaultMapping.generator(pgLogInsertReturningRawGenerator)(lname)
+		if nil != e {
+			t.Fatalf("Unable to get query: %v", e)
+		}
+
+		t1, e := p.Begin(ctx)
+		if nil != e {
+			t.Fatalf("Unable to begin: %v", e)
+		}
+		defer t1.Rollback(ctx)
+		var id1 int64
+		e = t1.QueryRow(ctx, query, []byte("lg7")).Scan(&id1)
+		if nil != e {
+			t.Fatalf("Unable to insert log: %v", e)
+		}
+
+		// the later transaction must wait the earlier one
+		done := make(chan int64, 1)
+		go func() {
+			var id2 int64
+			_ = p.BeginFunc(ctx, func(t2 pgx.Tx) error {
+				return t2.QueryRow(ctx, query, []byte("lg8")).Scan(&id2)
+			})
+			done <- id2
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		records, e := r(ctx, lname, id1-1, 10)
+		if nil != e {
+			t.Fatalf("Unable to read log: %v", e)
+		}
+		if 0 != len(records) {
+			t.Fatalf("Must not see logs after an uncommitted log: %v", records)
+		}
+
+		e = t1.Commit(ctx)
+		if nil != e {
+			t.Fatalf("Unable to commit: %v", e)
+		}
+		id2 := <-done
+		if id2 <= id1 {
+			t.Fatalf("Unexpected ids: %v, %v", id1, id2)
+		}
+
+		records, e = r(ctx, lname, id1-1, 10)
+		if nil != e {
+			t.Fatalf("Unable to read log: %v", e)
+		}
+		if 2 != len(records) || id1 != records[0].Id || id2 != records[1].Id {
+			t.Errorf("Unexpected records: %v", records)
+		}
+	})
+}
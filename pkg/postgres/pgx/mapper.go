@@ -0,0 +1,270 @@
+package pgx2kv
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
+)
+
+// pgGenerator is the query generator of the postgres driver(with extensions used by this package).
+type pgGenerator interface {
+	s2k.QueryGenerator
+	s2k.BucketQueryGenerator
+	s2k.RangeQueryGenerator
+	s2k.ScanQueryGenerator
+	s2k.CasQueryGenerator
+	s2k.TtlQueryGenerator
+	s2k.IncrQueryGenerator
+	s2k.StatsQueryGenerator
+	s2k.BucketOpQueryGenerator
+	s2k.PartitionQueryGenerator
+	s2k.NamespaceQueryGenerator
+}
+
+// PgxMapping creates functions which use the tables mapped by the BucketMapper.
+type PgxMapping struct {
+	gen       pgGenerator
+	mapper    s2k.BucketMapper
+	validator tableValidator
+	registry  string
+}
+
+// PgxMappingNew creates PgxMapping which uses the tables mapped by m(validated by v).
+//
+// The registry table(empty: no registry) records the mapping of the buckets;
+// LstBucket/HasBucket use the registry to get logical names(a missing registry has no buckets).
+// Logs are not recorded to the registry.
+func PgxMappingNew(v func(table string) error, m s2k.BucketMapper, registry string) PgxMapping {
+	return PgxMapping{
+		gen: pg.MappedQueryGeneratorNew(
+			pg.Validator(v),
+			pg.Validator(pgNamespaceValidator),
+			m,
+			registry,
+		).(pgGenerator),
+		mapper:    m,
+		validator: v,
+		registry:  registry,
+	}
+}
+
+var pgxDefaultMapping PgxMapping = PgxMappingNew(pgTableValidator, s2k.BucketMapperIdentity, "")
+
+// resolve gets the validated table of the bucket.
+func (x PgxMapping) resolve(bucket string) (table string, e error) {
+	table, e = x.mapper(bucket)
+	if nil != e {
+		return "", e
+	}
+	e = x.validator(table)
+	if nil != e {
+		return "", e
+	}
+	return table, pg.CheckIdentifiers(table)
+}
+
+// generator creates a generator which passes the mapped table to g.
+func (x PgxMapping) generator(g QueryGenerator) QueryGenerator {
+	return func(bucket string) (query string, e error) {
+		table, e := x.resolve(bucket)
+		if nil != e {
+			return "", e
+		}
+		return g(table)
+	}
+}
+
+func (x PgxMapping) storeBuilder(q pgxQuerier) s2k.Store {
+	return s2k.Store{
+		Get:       pgxGetBuilder(x.gen.Get)(q),
+		Set:       pgxSetBuilder(x.gen.Set)(q),
+		Add:       pgxAddBuilder(x.gen.Add)(q),
+		Del:       pgxDelBuilder(x.gen.Del)(q),
+		Lst:       pgxLstBuilder(x.gen.Lst)(q),
+		AddBucket: pgxBucketAddBuilder(x.gen.AddBucket)(q),
+		DelBucket: pgxBucketDelBuilder(x.gen.DelBucket)(q),
+	}
+}
+
+func (x PgxMapping) Store() func(p *pgxpool.Pool) s2k.Store {
+	return pool2querier(x.storeBuilder)
+}
+
+func (x PgxMapping) LstBucket() func(p *pgxpool.Pool) s2k.LstBucket {
+	return pool2querier(pgxLstBucketBuilder(x.gen.LstBucket))
+}
+
+func (x PgxMapping) HasBucket() func(p *pgxpool.Pool) s2k.HasBucket {
+	return pool2querier(pgxHasBucketBuilder(x.gen.HasBucket))
+}
+
+func (x PgxMapping) LstRange() func(p *pgxpool.Pool) s2k.LstRange {
+	return pool2querier(pgxLstRangeBuilder(x.gen.LstRange))
+}
+
+func (x PgxMapping) Scan() func(p *pgxpool.Pool) s2k.Scan {
+	return pool2querier(pgxScanBuilder(x.gen.Scan))
+}
+
+func (x PgxMapping) GetMany() func(p *pgxpool.Pool) s2k.GetMany {
+	return pool2querier(pgxGetManyBuilder(x.gen.Get))
+}
+
+func (x PgxMapping) BulkSet() func(p *pgxpool.Pool) s2k.SetMany {
+	return pgxBulkSetNew(x.gen.Set)
+}
+
+func (x PgxMapping) BatchUpsert() func(p *pgxpool.Pool) s2k.SetBatch {
+	return pgxBatchUpsertNew(bufQueryGeneratorNew(x.gen.Set))
+}
+
+func (x PgxMapping) BulkSetSingle(bucket string) func(p *pgxpool.Pool) s2k.SetMany2Bucket {
+	return pgxBulkSetSingleNew(QueryGenerator(x.gen.Set).build(bucket))
+}
+
+func (x PgxMapping) Pairs2BucketSingle(bucket string) func(p *pgxpool.Pool) s2k.Pairs2Bucket {
+	return pgxPairs2BucketSingleNew(QueryGenerator(x.gen.Set).build(bucket))
+}
+
+func (x PgxMapping) CompareAndSet() func(p *pgxpool.Pool) s2k.CompareAndSet {
+	return pool2querier(pgxCompareAndSetBuilder(x.gen.CasUpdate, x.gen.CasInsert))
+}
+
+func (x PgxMapping) GetVersion() func(p *pgxpool.Pool) s2k.GetVersion {
+	return pool2querier(pgxGetVersionBuilder(x.gen.GetVersion))
+}
+
+func (x PgxMapping) CompareAndSetVersion() func(p *pgxpool.Pool) s2k.CompareAndSetVersion {
+	return pool2querier(pgxCompareAndSetVersionBuilder(x.gen.CasVersionUpdate, x.gen.CasVersionInsert))
+}
+
+func (x PgxMapping) AddVersionBucket() func(p *pgxpool.Pool) s2k.AddBucket {
+	return pool2querier(pgxBucketAddBuilder(x.gen.AddVersionBucket))
+}
+
+func (x PgxMapping) TtlGet() func(p *pgxpool.Pool) s2k.Get {
+	return pool2querier(pgxGetBuilder(x.gen.TtlGet))
+}
+
+func (x PgxMapping) SetWithTTL() func(p *pgxpool.Pool) s2k.SetWithTTL {
+	return pool2querier(pgxSetWithTtlBuilder(x.gen.TtlSet))
+}
+
+func (x PgxMapping) Sweep() func(p *pgxpool.Pool) s2k.Sweep {
+	return pool2querier(pgxSweepBuilder(x.gen.Sweep))
+}
+
+func (x PgxMapping) AddTtlBucket() func(p *pgxpool.Pool) s2k.AddBucket {
+	return pool2querier(pgxBucketAddBuilder(x.gen.AddTtlBucket))
+}
+
+func (x PgxMapping) Incr() func(p *pgxpool.Pool) s2k.Incr {
+	return pool2querier(pgxIncrBuilder(x.gen.Incr))
+}
+
+func (x PgxMapping) BucketStats() func(p *pgxpool.Pool) s2k.BucketStats {
+	return pool2querier(pgxBucketStatsBuilder(x.gen.BucketStats))
+}
+
+func (x PgxMapping) RenameBucket() func(p *pgxpool.Pool) s2k.RenameBucket {
+	return pool2querier(pgxRenameBucketBuilder(x.gen.RenameBucket))
+}
+
+func (x PgxMapping) CopyBucket() func(p *pgxpool.Pool) s2k.CopyBucket {
+	return pool2querier(pgxCopyBucketBuilder(x.gen.CopyBucket))
+}
+
+func (x PgxMapping) TruncateBucket() func(p *pgxpool.Pool) s2k.TruncateBucket {
+	return pool2querier(pgxTruncateBucketBuilder(x.gen.TruncateBucket))
+}
+
+// AddPartitionedBucket creates AddBucket which creates buckets hash-partitioned into partitions tables.
+func (x PgxMapping) AddPartitionedBucket(partitions int) func(p *pgxpool.Pool) s2k.AddBucket {
+	return pool2querier(pgxBucketAddBuilder(func(bucket string) (string, error) {
+		return x.gen.AddPartitionedBucket(bucket, partitions)
+	}))
+}
+
+func (x PgxMapping) AddLog() func(p *pgxpool.Pool) s2k.AddLog {
+	return pgxLogAddNew(x.generator(pgAddLogRawGenerator))
+}
+
+// LogIns creates InsLog which appends logs.
+// Appends to one log are serialized until commit(ids become visible in order).
+func (x PgxMapping) LogIns(bucket string) func(p *pgxpool.Pool) s2k.InsLog {
+	return pgxLogInsertNew(x.generator(pgLogInsertRawGenerator).build(bucket))
+}
+
+// LogInsMany creates InsLogMany which inserts logs by a batch in a transaction.
+func (x PgxMapping) LogInsMany(bucket string) func(p *pgxpool.Pool) s2k.InsLogMany {
+	return pgxLogInsManyNew(x.generator(pgLogInsertReturningRawGenerator).build(bucket))
+}
+
+// LogInsNotify creates InsLog which notifies the id of the inserted log to subscribers.
+func (x PgxMapping) LogInsNotify(bucket string) func(p *pgxpool.Pool) s2k.InsLog {
+	query := x.generator(pgLogInsertNotifyRawGenerator).build(bucket)
+	channel, e := x.channel(bucket)
+	if nil != e {
+		query.e = e
+	}
+	return pool2querier(pgxLogInsertNotifyNew(query, channel))
+}
+
+// LogRead reads logs after the id(see PgxLogReadNew).
+func (x PgxMapping) LogRead() func(p *pgxpool.Pool) s2k.LogRead {
+	return pool2querier(pgxLogReadBuilder(x.generator(pgLogReadRawGenerator)))
+}
+
+// LogTail creates LogTail which polls the log every interval when caught up.
+func (x PgxMapping) LogTail(interval time.Duration, limit int64) func(p *pgxpool.Pool) s2k.LogTail {
+	read := x.LogRead()
+	return func(p *pgxpool.Pool) s2k.LogTail {
+		return s2k.LogTailNew(read(p), interval, limit)
+	}
+}
+
+// LogSubscribe creates LogTail which delivers new logs inserted by LogInsNotify.
+// Connection errors are retried after retry(doubled up to a minute; at most 10 times in a row),
+// resuming after the last acknowledged id.
+func (x PgxMapping) LogSubscribe(limit int64, retry time.Duration) func(p *pgxpool.Pool) s2k.LogTail {
+	read := x.LogRead()
+	return func(p *pgxpool.Pool) s2k.LogTail {
+		return logSubscribeNew(pgxLogListenerConnectNew(p), read(p), x.channel, limit, retry)
+	}
+}
+
+// AddLogWithOffset creates AddLog which creates the log and its offset table(<log>_ofs).
+func (x PgxMapping) AddLogWithOffset() func(p *pgxpool.Pool) s2k.AddLog {
+	return pgxLogAddNew(x.offsetGenerator(pgAddLogWithOffsetQuery))
+}
+
+func (x PgxMapping) OffsetCommit() func(p *pgxpool.Pool) s2k.OffsetCommit {
+	return pool2querier(pgxOffsetCommitBuilder(x.offsetGenerator(pgOffsetCommitQuery)))
+}
+
+func (x PgxMapping) OffsetPosition() func(p *pgxpool.Pool) s2k.OffsetPosition {
+	return pool2querier(pgxOffsetPositionBuilder(x.offsetGenerator(pgOffsetPositionQuery)))
+}
+
+// LogConsume creates an at-least-once LogConsume which reads logs using tail(e.g. LogTail).
+// Logs must be appended by this package(see PgxLogConsumeNew).
+func (x PgxMapping) LogConsume(tail func(p *pgxpool.Pool) s2k.LogTail) func(p *pgxpool.Pool) s2k.LogConsume {
+	position := x.OffsetPosition()
+	commit := x.OffsetCommit()
+	return func(p *pgxpool.Pool) s2k.LogConsume {
+		return s2k.LogConsumeNew(tail(p), position(p), commit(p))
+	}
+}
+
+// AddLogWithTime creates AddLog which records insertion timestamps(required by Retention.MaxAge).
+func (x PgxMapping) AddLogWithTime() func(p *pgxpool.Pool) s2k.AddLog {
+	return pgxLogAddNew(x.generator(pgAddLogWithTimeRawGenerator))
+}
+
+// Compact creates Compact; Retention.BelowCommitted requires the offset table(AddLogWithOffset).
+func (x PgxMapping) Compact() func(p *pgxpool.Pool) s2k.Compact {
+	return pool2querier(pgxCompactBuilder(x.retentionGenerator(pgCompactQuery)))
+}
@@ -0,0 +1,324 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestMapper(t *testing.T) {
+	t.Parallel()
+
+	var m s2k.BucketMapper = s2k.HashMapperNew("h_", 59)
+	long := "test_mapper_" + strings.Repeat("x", 64)
+	hashed, _ := m(long)
+	registry := "test_mapper_registry"
+	var x PgxMapping = PgxMappingNew(pgTableValidator, m, registry)
+
+	t.Run("mapped", func(t *testing.T) {
+		t.Parallel()
+		q, e := x.gen.Get(long)
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(q, `FROM "`+hashed+`"`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("registry", func(t *testing.T) {
+		t.Parallel()
+		q, e := x.gen.AddBucket(long)
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(q, `VALUES ('`+long+`', '`+hashed+`')`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("invalid mapped name", func(t *testing.T) {
+		t.Parallel()
+		_, e := PgxMappingNew(pgTableValidator, s2k.HashMapperNew("0_", 59), "").gen.Get(long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+
+	var store s2k.Store = x.Store()(p)
+	var lst s2k.LstBucket = x.LstBucket()(p)
+	var has s2k.HasBucket = x.HasBucket()(p)
+
+	_ = store.DelBucket(ctx, long)
+
+	// non parallel
+	t.Run("add", func(t *testing.T) {
+		e := store.AddBucket(ctx, long)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+		found, e := has(ctx, long)
+		if nil != e {
+			t.Errorf("Unable to check bucket: %v", e)
+		}
+		if !found {
+			t.Errorf("Bucket must exist")
+		}
+	})
+
+	t.Run("lst", func(t *testing.T) {
+		found := false
+		e := lst(ctx, func(bucket string) error {
+			found = found || long == bucket
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list buckets: %v", e)
+		}
+		if !found {
+			t.Errorf("Logical name must be listed")
+		}
+	})
+
+	t.Run("set/get", func(t *testing.T) {
+		e := store.Set(ctx, long, []byte("k"), []byte("v"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, e := store.Get(ctx, long, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v"))
+	})
+
+	t.Run("del", func(t *testing.T) {
+		e := store.DelBucket(ctx, long)
+		if nil != e {
+			t.Errorf("Unable to remove bucket: %v", e)
+		}
+		found, _ := has(ctx, long)
+		if found {
+			t.Errorf("Bucket must be unregistered")
+		}
+	})
+}
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+
+	var m s2k.BucketMapper = s2k.HashMapperNew("h_", 40)
+	long := "test_mapping_" + strings.Repeat("x", 64)
+	hashed, _ := m(long)
+	var x PgxMapping = PgxMappingNew(pgTableValidator, m, "test_mapping_registry")
+
+	t.Run("queries", func(t *testing.T) {
+		t.Parallel()
+
+		// without the registry(which records the logical name)
+		var y PgxMapping = PgxMappingNew(pgTableValidator, m, "")
+		generators := map[string]QueryGenerator{
+			"Scan":        y.gen.Scan,
+			"CasUpdate":   y.gen.CasUpdate,
+			"TtlSet":      y.gen.TtlSet,
+			"Incr":        y.gen.Incr,
+			"BucketStats": y.gen.BucketStats,
+			"Truncate":    y.gen.TruncateBucket,
+			"LogRead":     y.generator(pgLogReadRawGenerator),
+			"LogIns":      y.generator(pgLogInsertRawGenerator),
+			"Offset":      y.offsetGenerator(pgOffsetCommitQuery),
+			"Range": func(bucket string) (string, error) {
+				return y.gen.LstRange(bucket, s2k.Range{Limit: 1})
+			},
+			"Partition": func(bucket string) (string, error) {
+				return y.gen.AddPartitionedBucket(bucket, 2)
+			},
+			"Compact": func(bucket string) (string, error) {
+				return y.retentionGenerator(pgCompactQuery)(bucket, s2k.Retention{MaxRows: 1})
+			},
+		}
+		for name, g := range generators {
+			name := name
+			g := g
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				q, e := g(long)
+				if nil != e {
+					t.Fatalf("Must accept long bucket: %v", e)
+				}
+				if !strings.Contains(q, `"`+hashed) {
+					t.Errorf("Must use the mapped table: %s", q)
+				}
+				if strings.Contains(q, long) {
+					t.Errorf("Must not use the logical name: %s", q)
+				}
+			})
+		}
+	})
+
+	t.Run("registry", func(t *testing.T) {
+		t.Parallel()
+
+		q, e := x.gen.AddVersionBucket(long)
+		if nil != e {
+			t.Fatalf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(q, `VALUES ('`+long+`', '`+hashed+`')`) {
+			t.Errorf("Must record the mapping: %s", q)
+		}
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		t.Parallel()
+
+		channel, e := x.channel(long)
+		if nil != e {
+			t.Fatalf("Must accept long bucket: %v", e)
+		}
+		if hashed != channel {
+			t.Errorf("Must use the mapped table: %s", channel)
+		}
+	})
+
+	t.Run("invalid mapped name", func(t *testing.T) {
+		t.Parallel()
+
+		var i s2k.Incr = PgxMappingNew(pgTableValidator, s2k.HashMapperNew("0_", 40), "").Incr()(nil)
+		_, e := i(context.Background(), long, []byte("k"), 1)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	var store s2k.Store = x.Store()(p)
+	var has s2k.HasBucket = x.HasBucket()(p)
+
+	_ = store.DelBucket(ctx, long)
+	t.Cleanup(func() { _ = store.DelBucket(ctx, long) })
+
+	// non parallel
+	t.Run("add", func(t *testing.T) {
+		e := store.AddBucket(ctx, long)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+		found, e := has(ctx, long)
+		if nil != e || !found {
+			t.Errorf("Bucket must be registered: %v", e)
+		}
+		e = store.Set(ctx, long, []byte("k"), []byte("v"))
+		if nil != e {
+			t.Fatalf("Unable to set: %v", e)
+		}
+	})
+
+	t.Run("incr/range", func(t *testing.T) {
+		i, e := x.Incr()(p)(ctx, long, []byte("n"), 3)
+		if nil != e || 3 != i {
+			t.Errorf("Unexpected counter: %v, %v", i, e)
+		}
+		var keys []string
+		e = x.LstRange()(p)(ctx, long, s2k.Range{Limit: 10}, func(key []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list keys: %v", e)
+		}
+		if 2 != len(keys) {
+			t.Errorf("Unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		lttl := long + "_ttl"
+		_ = store.DelBucket(ctx, lttl)
+		t.Cleanup(func() { _ = store.DelBucket(ctx, lttl) })
+
+		e := x.AddTtlBucket()(p)(ctx, lttl)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+		e = x.SetWithTTL()(p)(ctx, lttl, []byte("k"), []byte("v"), time.Hour)
+		if nil != e {
+			t.Fatalf("Unable to set: %v", e)
+		}
+		got, e := x.TtlGet()(p)(ctx, lttl, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v"))
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		s, e := x.BucketStats()(p)(ctx, long)
+		if nil != e {
+			t.Errorf("Unable to get stats: %v", e)
+		}
+		if 2 != s.Rows {
+			t.Errorf("Unexpected stats: %v", s)
+		}
+	})
+
+	t.Run("log", func(t *testing.T) {
+		lname := long + "_log"
+		_ = store.DelBucket(ctx, lname)
+		t.Cleanup(func() { _ = store.DelBucket(ctx, lname) })
+
+		e := x.AddLogWithOffset()(p)(ctx, lname)
+		if nil != e {
+			t.Fatalf("Unable to create log: %v", e)
+		}
+		lt, _ := m(lname)
+		t.Cleanup(func() { _ = PgxDelBucketNew(p)(ctx, bucket2offset(lt)) })
+
+		e = x.LogIns(lname)(p)(ctx, []byte("lg0"))
+		if nil != e {
+			t.Fatalf("Unable to insert log: %v", e)
+		}
+		records, e := x.LogRead()(p)(ctx, lname, 0, 10)
+		if nil != e || 1 != len(records) {
+			t.Fatalf("Unexpected records: %v, %v", records, e)
+		}
+		e = x.OffsetCommit()(p)(ctx, lname, "g", records[0].Id)
+		if nil != e {
+			t.Errorf("Unable to commit: %v", e)
+		}
+		id, e := x.OffsetPosition()(p)(ctx, lname, "g")
+		if nil != e || records[0].Id != id {
+			t.Errorf("Unexpected position: %v, %v", id, e)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+type namespaceQueryGenerator func(namespace string) (query string, e error)
+
+var pgAddNamespaceQueryGenerator namespaceQueryGenerator = pgxDefaultMapping.gen.AddNamespace
+
+var pgDropNamespaceQueryGenerator namespaceQueryGenerator = pgxDefaultMapping.gen.DropNamespace
+
+func pgxNamespaceExecBuilder(qgen namespaceQueryGenerator) func(q pgxQuerier) func(ctx context.Context, namespace string) error {
+	return func(q pgxQuerier) func(ctx context.Context, namespace string) error {
+		return func(ctx context.Context, namespace string) error {
+			query, e := qgen(namespace)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+func pgxAddNamespaceBuilder(qgen namespaceQueryGenerator) func(q pgxQuerier) s2k.AddNamespace {
+	return func(q pgxQuerier) s2k.AddNamespace { return pgxNamespaceExecBuilder(qgen)(q) }
+}
+
+func pgxDropNamespaceBuilder(qgen namespaceQueryGenerator) func(q pgxQuerier) s2k.DropNamespace {
+	return func(q pgxQuerier) s2k.DropNamespace { return pgxNamespaceExecBuilder(qgen)(q) }
+}
+
+var PgxAddNamespaceNew func(p *pgxpool.Pool) s2k.AddNamespace = pool2querier(
+	pgxAddNamespaceBuilder(pgAddNamespaceQueryGenerator),
+)
+
+var PgxDropNamespaceNew func(p *pgxpool.Pool) s2k.DropNamespace = pool2querier(
+	pgxDropNamespaceBuilder(pgDropNamespaceQueryGenerator),
+)
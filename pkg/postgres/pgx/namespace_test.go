@@ -0,0 +1,139 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("qualified bucket", func(t *testing.T) {
+		t.Parallel()
+		q, e := pgGetQueryGenerator("ns0.t0")
+		if nil != e {
+			t.Errorf("Must accept qualified bucket: %v", e)
+		}
+		if !strings.Contains(q, `FROM "ns0"."t0"`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+		q, e = pgxDefaultMapping.gen.AddBucket("ns0.t0")
+		if nil != e {
+			t.Errorf("Must accept qualified bucket: %v", e)
+		}
+		if !strings.Contains(q, `CONSTRAINT "t0_pkc"`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+		for _, b := range []string{"ns0.", ".t0", "ns0.t0.t1", `ns0."t0"`} {
+			_, e := pgGetQueryGenerator(b)
+			if !errors.Is(e, s2k.ErrInvalidBucket) {
+				t.Errorf("Must reject invalid bucket(%s): %v", b, e)
+			}
+		}
+	})
+
+	t.Run("rename across namespaces", func(t *testing.T) {
+		t.Parallel()
+		_, e := pgxDefaultMapping.gen.RenameBucket("ns0.t0", "ns1.t0")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject rename across namespaces: %v", e)
+		}
+	})
+
+	t.Run("invalid namespace", func(t *testing.T) {
+		t.Parallel()
+		var a s2k.AddNamespace = pgxAddNamespaceBuilder(pgAddNamespaceQueryGenerator)(nil)
+		e := a(context.Background(), "ns0.t0")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject invalid namespace: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	ns := "test_ns"
+	bucket := ns + ".user" // reserved word
+
+	var addNs s2k.AddNamespace = PgxAddNamespaceNew(p)
+	var dropNs s2k.DropNamespace = PgxDropNamespaceNew(p)
+	var store s2k.Store = PgxStoreNew(p)
+	var has s2k.HasBucket = PgxHasBucketNew(p)
+
+	_ = store.DelBucket(ctx, bucket)
+	_ = dropNs(ctx, ns)
+
+	// non parallel
+	t.Run("add", func(t *testing.T) {
+		e := addNs(ctx, ns)
+		if nil != e {
+			t.Fatalf("Unable to create namespace: %v", e)
+		}
+		e = store.AddBucket(ctx, bucket)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+		found, e := has(ctx, bucket)
+		if nil != e {
+			t.Errorf("Unable to check bucket: %v", e)
+		}
+		if !found {
+			t.Errorf("Bucket must exist")
+		}
+		found = false
+		e = PgxLstBucketNew(p)(ctx, func(b string) error {
+			found = found || bucket == b
+			return nil
+		})
+		if nil != e || !found {
+			t.Errorf("Namespaced bucket must be listed: %v", e)
+		}
+	})
+
+	t.Run("set/get", func(t *testing.T) {
+		e := store.Set(ctx, bucket, []byte("k"), []byte("v"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, e := store.Get(ctx, bucket, []byte("k"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("v"))
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		e := dropNs(ctx, ns)
+		if nil == e {
+			t.Errorf("Non empty namespace must not be dropped")
+		}
+		e = store.DelBucket(ctx, bucket)
+		if nil != e {
+			t.Errorf("Unable to remove bucket: %v", e)
+		}
+		e = dropNs(ctx, ns)
+		if nil != e {
+			t.Errorf("Unable to drop namespace: %v", e)
+		}
+	})
+}
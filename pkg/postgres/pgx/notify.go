@@ -0,0 +1,180 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// channel gets the channel name of the log(the mapped table; channel names are limited to 63 bytes).
+func (x PgxMapping) channel(bucket string) (string, error) {
+	table, e := x.resolve(bucket)
+	if nil != e {
+		return "", e
+	}
+	if 63 < len(table) {
+		return "", fmt.Errorf("Too long channel name(%s): %w", table, s2k.ErrInvalidBucket)
+	}
+	return table, nil
+}
+
+var pgLogInsertNotifyRawGenerator QueryGenerator = strQueryGeneratorNewMust(pgLogLockQuery + `,
+	inserted AS (
+	  INSERT INTO {{.tableName}} (lg)
+	  SELECT $1::BYTEA FROM locked
+	  RETURNING id
+	)
+	SELECT PG_NOTIFY($2, id::TEXT) FROM inserted
+`)
+
+func pgxLogInsertNotifyNew(query builtQuery, channel string) func(q pgxQuerier) s2k.InsLog {
+	return func(q pgxQuerier) s2k.InsLog {
+		return func(ctx context.Context, lg []byte) error {
+			if nil != query.e {
+				return query.e
+			}
+			_, e := q.Exec(ctx, query.query, lg, channel)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+// PgxLogInsNotifyBuilder creates InsLog which notifies the id of the inserted log to subscribers.
+func PgxLogInsNotifyBuilder(bucketName string) func(p *pgxpool.Pool) s2k.InsLog {
+	return pgxDefaultMapping.LogInsNotify(bucketName)
+}
+
+type logListener interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// logListenerConnect creates a connection which listens the channel.
+type logListenerConnect func(ctx context.Context, channel string) (logListener, error)
+
+func pgxLogListenerConnectNew(p *pgxpool.Pool) logListenerConnect {
+	return func(ctx context.Context, channel string) (logListener, error) {
+		pc, e := p.Acquire(ctx)
+		if nil != e {
+			return nil, ErrorConvert(e)
+		}
+		// listening connections must not be reused by others
+		var conn *pgx.Conn = pc.Hijack()
+		_, e = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+		if nil != e {
+			_ = conn.Close(context.Background())
+			return nil, ErrorConvert(e)
+		}
+		return conn, nil
+	}
+}
+
+type logCallbackError struct{ e error }
+
+func (c logCallbackError) Error() string { return c.e.Error() }
+
+// logCatchUp passes all records after afterId to cb and returns the last acknowledged id.
+func logCatchUp(ctx context.Context, r s2k.LogRead, bucket string, afterId, limit int64, cb func(s2k.LogRecord) error) (int64, error) {
+	for {
+		records, e := r(ctx, bucket, afterId, limit)
+		if nil != e {
+			return afterId, e
+		}
+		for _, rec := range records {
+			e = cb(rec)
+			if nil != e {
+				return afterId, logCallbackError{e}
+			}
+			afterId = rec.Id
+		}
+		if int64(len(records)) < limit {
+			return afterId, nil
+		}
+	}
+}
+
+func logListen(ctx context.Context, l logListener, r s2k.LogRead, bucket string, afterId, limit int64, cb func(s2k.LogRecord) error) (int64, error) {
+	defer l.Close(context.Background())
+	for {
+		// records inserted before the notification arrives are read here
+		acked, e := logCatchUp(ctx, r, bucket, afterId, limit, cb)
+		afterId = acked
+		if nil != e {
+			return afterId, e
+		}
+		_, e = l.WaitForNotification(ctx)
+		if nil != e {
+			return afterId, ErrorConvert(e)
+		}
+	}
+}
+
+// logRetryMax is the number of consecutive connection failures before the subscriber gives up.
+const logRetryMax = 10
+
+// logBackoffMax caps the wait between reconnects(doubled on each consecutive failure).
+const logBackoffMax = time.Minute
+
+// logSubscribeNew creates LogTail which waits notifications and reconnects after retry on connection errors.
+func logSubscribeNew(connect logListenerConnect, r s2k.LogRead, logChannel QueryGenerator, limit int64, retry time.Duration) s2k.LogTail {
+	return func(ctx context.Context, bucket string, afterId int64, cb func(s2k.LogRecord) error) error {
+		if limit < 1 {
+			return fmt.Errorf("Invalid limit: %v", limit)
+		}
+		channel, e := logChannel(bucket)
+		if nil != e {
+			return e
+		}
+		var failures int = 0
+		var wait time.Duration = retry
+		for {
+			l, e := connect(ctx, channel)
+			if nil == e {
+				failures = 0
+				wait = retry
+				afterId, e = logListen(ctx, l, r, bucket, afterId, limit, cb)
+			}
+			var ce logCallbackError
+			if errors.As(e, &ce) {
+				return ce.e
+			}
+			if nil != ctx.Err() {
+				return ctx.Err()
+			}
+			if errors.Is(e, s2k.ErrBucketNotFound) {
+				return e
+			}
+			failures += 1
+			if logRetryMax <= failures {
+				return fmt.Errorf("Unable to subscribe after %v retries: %w", failures, e)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait = minDuration(2*wait, logBackoffMax)
+		}
+	}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// PgxLogSubscribeNew creates LogTail which delivers new logs inserted by PgxLogInsNotifyBuilder.
+// Connection errors are retried after retry(doubled up to a minute; at most 10 times in a row),
+// resuming after the last acknowledged id.
+func PgxLogSubscribeNew(limit int64, retry time.Duration) func(p *pgxpool.Pool) s2k.LogTail {
+	return pgxDefaultMapping.LogSubscribe(limit, retry)
+}
@@ -0,0 +1,222 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+type testLogListener struct {
+	notified <-chan struct{}
+	closed   func()
+}
+
+func (l testLogListener) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case _, ok := <-l.notified:
+		if !ok {
+			return nil, errors.New("connection lost")
+		}
+		return &pgconn.Notification{}, nil
+	}
+}
+
+func (l testLogListener) Close(_ context.Context) error {
+	l.closed()
+	return nil
+}
+
+func TestLogSubscribe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var tail s2k.LogTail = logSubscribeNew(nil, nil, pgxDefaultMapping.channel, 0, time.Millisecond)
+		e := tail(context.Background(), "l0", 0, nil)
+		if nil == e {
+			t.Errorf("Must reject invalid limit")
+		}
+
+		tail = logSubscribeNew(nil, nil, pgxDefaultMapping.channel, 1, time.Millisecond)
+		e = tail(context.Background(), "0invalid", 0, nil)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		t.Parallel()
+
+		var lock sync.Mutex
+		var logs []string = []string{"lg1", "lg2", "lg3"}
+		var r s2k.LogRead = func(_ context.Context, _ string, afterId, limit int64) ([]s2k.LogRecord, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			var records []s2k.LogRecord
+			for i := afterId; i < int64(len(logs)) && int64(len(records)) < limit; i++ {
+				records = append(records, s2k.LogRecord{Id: i + 1, Payload: []byte(logs[i])})
+			}
+			return records, nil
+		}
+
+		notified := make(chan struct{})
+		var connects, closes int
+		var connect logListenerConnect = func(_ context.Context, channel string) (logListener, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			connects += 1
+			switch connects {
+			case 1:
+				return nil, errors.New("unable to connect")
+			case 2:
+				lost := make(chan struct{})
+				close(lost)
+				return testLogListener{notified: lost, closed: func() { closes += 1 }}, nil
+			default:
+				return testLogListener{notified: notified, closed: func() { closes += 1 }}, nil
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var got []string
+		var tail s2k.LogTail = logSubscribeNew(connect, r, pgxDefaultMapping.channel, 2, time.Millisecond)
+		e := tail(ctx, "l0", 1, func(rec s2k.LogRecord) error {
+			got = append(got, fmt.Sprintf("%d:%s", rec.Id, rec.Payload))
+			switch len(got) {
+			case 2:
+				go func() {
+					lock.Lock()
+					logs = append(logs, "lg4")
+					lock.Unlock()
+					notified <- struct{}{}
+				}()
+			case 3:
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(e, context.Canceled) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		expected := "[2:lg2 3:lg3 4:lg4]"
+		if expected != fmt.Sprint(got) {
+			t.Errorf("Unexpected logs: %v", got)
+		}
+		if 3 != connects || 2 != closes {
+			t.Errorf("Unexpected connects/closes: %v/%v", connects, closes)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		t.Parallel()
+
+		var r s2k.LogRead = func(_ context.Context, _ string, afterId, _ int64) ([]s2k.LogRecord, error) {
+			return []s2k.LogRecord{{Id: afterId + 1}}, nil
+		}
+		var connect logListenerConnect = func(_ context.Context, _ string) (logListener, error) {
+			return testLogListener{closed: func() {}}, nil
+		}
+		errStop := errors.New("stop")
+		e := logSubscribeNew(connect, r, pgxDefaultMapping.channel, 2, time.Millisecond)(context.Background(), "l0", 0, func(_ s2k.LogRecord) error {
+			return errStop
+		})
+		if !errors.Is(e, errStop) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+
+	t.Run("give up", func(t *testing.T) {
+		t.Parallel()
+
+		errUnreachable := errors.New("unable to connect")
+		var connects int
+		var connect logListenerConnect = func(_ context.Context, _ string) (logListener, error) {
+			connects += 1
+			return nil, errUnreachable
+		}
+		e := logSubscribeNew(connect, nil, pgxDefaultMapping.channel, 2, time.Microsecond)(context.Background(), "l0", 0, nil)
+		if !errors.Is(e, errUnreachable) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if logRetryMax != connects {
+			t.Errorf("Unexpected connects: %v", connects)
+		}
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		t.Parallel()
+		if 2*time.Millisecond != minDuration(2*time.Millisecond, logBackoffMax) {
+			t.Errorf("Must double the wait")
+		}
+		if logBackoffMax != minDuration(2*logBackoffMax, logBackoffMax) {
+			t.Errorf("Must cap the wait")
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	lname := "test_log_notify"
+	bg := context.Background()
+
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+	var ins s2k.InsLog = PgxLogInsNotifyBuilder(lname)(p)
+
+	_ = db(bg, lname)
+	e = PgxAddLogNew(p)(bg, lname)
+	if nil != e {
+		t.Fatalf("Unable to create log: %v", e)
+	}
+	t.Cleanup(func() { _ = db(bg, lname) })
+
+	e = ins(bg, []byte("lg0"))
+	if nil != e {
+		t.Fatalf("Unable to insert log: %v", e)
+	}
+
+	// non parallel
+	t.Run("subscribe", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(bg, 10*time.Second)
+		defer cancel()
+
+		var got []string
+		e := PgxLogSubscribeNew(10, time.Second)(p)(ctx, lname, 0, func(rec s2k.LogRecord) error {
+			got = append(got, string(rec.Payload))
+			switch len(got) {
+			case 1:
+				go func() { _ = ins(bg, []byte("lg1")) }()
+			case 2:
+				cancel()
+			}
+			return nil
+		})
+		if !errors.Is(e, context.Canceled) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if "[lg0 lg1]" != fmt.Sprint(got) {
+			t.Errorf("Unexpected logs: %v", got)
+		}
+	})
+}
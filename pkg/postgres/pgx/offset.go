@@ -0,0 +1,112 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
+)
+
+// bucket2offset gets the name of the offset table of the log(in the same namespace).
+func bucket2offset(bucket string) string { return bucket + "_ofs" }
+
+// offset2grp gets the name of the primary key constraint of the offset table.
+// The name must not end with _pkc(offset tables are not buckets).
+func offset2grp(offset string) string {
+	_, name := pg.SplitBucket(offset)
+	return pg.QuoteIdentifier(name + "_grp")
+}
+
+// offsetGenerator creates a generator which uses the offset table of the mapped log.
+func (x PgxMapping) offsetGenerator(s string) QueryGenerator {
+	t := str2templateMust("root")(s)
+	return x.generator(func(table string) (query string, e error) {
+		var offset string = bucket2offset(table)
+		e = x.validator(offset)
+		if nil != e {
+			return "", e
+		}
+		e = pg.CheckIdentifiers(offset) // also covers <offset>_grp
+		if nil != e {
+			return "", e
+		}
+		data := bucketData(table)
+		data["offsetName"] = pg.BucketTable(offset)
+		data["offsetGrp"] = offset2grp(offset)
+		var buf strings.Builder
+		e = t.ExecuteTemplate(&buf, "root", data)
+		return buf.String(), e
+	})
+}
+
+const pgAddLogWithOffsetQuery = `
+	CREATE TABLE IF NOT EXISTS {{.tableName}} (
+	  id BIGSERIAL,
+	  lg BYTEA,
+	  CONSTRAINT {{.pkcName}} PRIMARY KEY(id)
+	);
+	CREATE TABLE IF NOT EXISTS {{.offsetName}} (
+	  grp TEXT,
+	  id BIGINT NOT NULL,
+	  CONSTRAINT {{.offsetGrp}} PRIMARY KEY(grp)
+	)
+`
+
+const pgOffsetCommitQuery = `
+	INSERT INTO {{.offsetName}} (grp, id)
+	VALUES($1, $2)
+	ON CONFLICT (grp)
+	DO UPDATE SET id=EXCLUDED.id
+`
+
+const pgOffsetPositionQuery = `
+	SELECT id FROM {{.offsetName}}
+	WHERE grp=$1
+	LIMIT 1
+`
+
+func pgxOffsetCommitBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.OffsetCommit {
+	return func(q pgxQuerier) s2k.OffsetCommit {
+		return func(ctx context.Context, bucket string, group string, id int64) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query, group, id)
+			return ErrorConvert(e)
+		}
+	}
+}
+
+func pgxOffsetPositionBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.OffsetPosition {
+	return func(q pgxQuerier) s2k.OffsetPosition {
+		return func(ctx context.Context, bucket string, group string) (id int64, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return 0, e
+			}
+			e = ErrorConvert(q.QueryRow(ctx, query, group).Scan(&id))
+			if errors.Is(e, s2k.ErrNotFound) {
+				return 0, nil
+			}
+			return id, e
+		}
+	}
+}
+
+// PgxAddLogWithOffsetNew creates AddLog which creates the log and its offset table(<log>_ofs).
+var PgxAddLogWithOffsetNew func(p *pgxpool.Pool) s2k.AddLog = pgxDefaultMapping.AddLogWithOffset()
+
+var PgxOffsetCommitNew func(p *pgxpool.Pool) s2k.OffsetCommit = pgxDefaultMapping.OffsetCommit()
+
+var PgxOffsetPositionNew func(p *pgxpool.Pool) s2k.OffsetPosition = pgxDefaultMapping.OffsetPosition()
+
+// PgxLogConsumeNew creates an at-least-once LogConsume which reads logs using tail(e.g. PgxLogTailNew).
+// Logs must be appended by this package; other appends may commit ids out of order(see PgxLogReadNew).
+func PgxLogConsumeNew(tail func(p *pgxpool.Pool) s2k.LogTail) func(p *pgxpool.Pool) s2k.LogConsume {
+	return pgxDefaultMapping.LogConsume(tail)
+}
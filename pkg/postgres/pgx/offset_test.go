@@ -0,0 +1,170 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestOffset(t *testing.T) {
+	t.Parallel()
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+
+		q, e := pgxDefaultMapping.offsetGenerator(pgOffsetCommitQuery)("ns.l0")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if !strings.Contains(q, `INSERT INTO "ns"."l0_ofs"`) || !strings.Contains(q, `ON CONFLICT (grp)`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("not a bucket", func(t *testing.T) {
+		t.Parallel()
+
+		q, e := pgxDefaultMapping.offsetGenerator(pgAddLogWithOffsetQuery)("l0")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if !strings.Contains(q, `CONSTRAINT "l0_ofs_grp" PRIMARY KEY(grp)`) || strings.Contains(q, `"l0_ofs_pkc"`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		_, e := pgxDefaultMapping.offsetGenerator(pgAddLogWithOffsetQuery)("0invalid")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+
+		// offset table name too long
+		_, e = pgxDefaultMapping.offsetGenerator(pgOffsetPositionQuery)(strings.Repeat("l", 58))
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("too long offset", func(t *testing.T) {
+		t.Parallel()
+
+		var x PgxMapping = PgxMappingNew(func(_ string) error { return nil }, s2k.BucketMapperIdentity, "")
+		_, e := x.offsetGenerator(pgAddLogWithOffsetQuery)(strings.Repeat("l", 55))
+		if nil != e {
+			t.Errorf("Must accept the longest log: %v", e)
+		}
+		_, e = x.offsetGenerator(pgAddLogWithOffsetQuery)(strings.Repeat("l", 57))
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long offset table: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	lname := "test_log_offset"
+	bg := context.Background()
+
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+	cleanup := func() {
+		_ = db(bg, lname)
+		_ = db(bg, bucket2offset(lname))
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	e = PgxAddLogWithOffsetNew(p)(bg, lname)
+	if nil != e {
+		t.Fatalf("Unable to create log: %v", e)
+	}
+
+	var ins s2k.InsLog = PgxLogInsBuilder(lname)(p)
+	for i := 0; i < 3; i++ {
+		e := ins(bg, []byte(fmt.Sprintf("lg%d", i)))
+		if nil != e {
+			t.Fatalf("Unable to insert log: %v", e)
+		}
+	}
+
+	var pos s2k.OffsetPosition = PgxOffsetPositionNew(p)
+	var commit s2k.OffsetCommit = PgxOffsetCommitNew(p)
+
+	// non parallel
+	t.Run("not a bucket", func(t *testing.T) {
+		found, e := PgxHasBucketNew(p)(bg, bucket2offset(lname))
+		if nil != e || found {
+			t.Errorf("Offset table must not be a bucket: %v, %v", found, e)
+		}
+	})
+
+	t.Run("position", func(t *testing.T) {
+		id, e := pos(bg, lname, "g0")
+		if nil != e || 0 != id {
+			t.Errorf("Unexpected position: %v, %v", id, e)
+		}
+	})
+
+	t.Run("consume", func(t *testing.T) {
+		consume := PgxLogConsumeNew(PgxLogTailNew(10*time.Millisecond, 2))(p)
+		errStop := errors.New("stop")
+		var got []string
+		e := consume(bg, lname, "g0", func(rec s2k.LogRecord) error {
+			if "lg2" == string(rec.Payload) {
+				return errStop
+			}
+			got = append(got, string(rec.Payload))
+			return nil
+		})
+		if !errors.Is(e, errStop) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if "[lg0 lg1]" != fmt.Sprint(got) {
+			t.Errorf("Unexpected logs: %v", got)
+		}
+
+		ctx, cancel := context.WithTimeout(bg, 10*time.Second)
+		defer cancel()
+		got = nil
+		e = consume(ctx, lname, "g0", func(rec s2k.LogRecord) error {
+			got = append(got, string(rec.Payload))
+			cancel()
+			return nil
+		})
+		if !errors.Is(e, context.Canceled) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if "[lg2]" != fmt.Sprint(got) {
+			t.Errorf("Unexpected logs: %v", got)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		e := commit(bg, lname, "g1", 1)
+		if nil != e {
+			t.Errorf("Unable to commit: %v", e)
+		}
+		id, e := pos(bg, lname, "g1")
+		if nil != e || 1 != id {
+			t.Errorf("Unexpected position: %v, %v", id, e)
+		}
+	})
+}
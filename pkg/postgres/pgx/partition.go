@@ -0,0 +1,12 @@
+package pgx2kv
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+// PgxAddPartitionedBucketNew creates AddBucket which creates buckets hash-partitioned into partitions tables.
+func PgxAddPartitionedBucketNew(partitions int) func(p *pgxpool.Pool) s2k.AddBucket {
+	return pgxDefaultMapping.AddPartitionedBucket(partitions)
+}
@@ -0,0 +1,140 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestPartitionedBucket(t *testing.T) {
+	t.Parallel()
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+		q, e := pgxDefaultMapping.gen.AddPartitionedBucket("t0", 2)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		expected := `PARTITION OF "t0" FOR VALUES WITH (MODULUS 2, REMAINDER 1)`
+		if !strings.Contains(q, expected) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, e := pgxDefaultMapping.gen.AddPartitionedBucket("t0", 0)
+		if nil == e {
+			t.Errorf("Must reject invalid number of partitions")
+		}
+		_, e = pgxDefaultMapping.gen.AddPartitionedBucket(strings.Repeat("t", 59), 128)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long partition name: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	tname := "test_partitioned"
+
+	var ab s2k.AddBucket = PgxAddPartitionedBucketNew(4)(p)
+	var store s2k.Store = PgxStoreNew(p)
+
+	_ = store.DelBucket(ctx, tname)
+
+	// non parallel
+	t.Run("add", func(t *testing.T) {
+		e := ab(ctx, tname)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+	})
+
+	t.Run("set/get/lst", func(t *testing.T) {
+		for _, k := range []string{"a", "b", "c", "d", "e"} {
+			e := store.Set(ctx, tname, []byte(k), []byte("v"))
+			if nil != e {
+				t.Errorf("Unable to set: %v", e)
+			}
+		}
+		e := store.Set(ctx, tname, []byte("a"), []byte("w"))
+		if nil != e {
+			t.Errorf("Unable to upsert: %v", e)
+		}
+		got, e := store.Get(ctx, tname, []byte("a"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("w"))
+		var keys []string
+		e = store.Lst(ctx, tname, func(key []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to list: %v", e)
+		}
+		if "a,b,c,d,e" != strings.Join(keys, ",") {
+			t.Errorf("Unexpected keys: %v", keys)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		s, e := PgxBucketStatsNew(p)(ctx, tname)
+		if nil != e {
+			t.Fatalf("Unable to get stats: %v", e)
+		}
+		if 5 != s.Rows || s.TotalBytes < 1 {
+			t.Errorf("Must sum partitions: %v", s)
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		renamed := tname + "_renamed"
+		_ = store.DelBucket(ctx, renamed)
+		t.Cleanup(func() { _ = store.DelBucket(ctx, renamed) })
+
+		e := PgxRenameBucketNew(p)(ctx, tname, renamed)
+		if nil != e {
+			t.Fatalf("Unable to rename: %v", e)
+		}
+
+		// partitions must be moved with the parent
+		e = ab(ctx, tname)
+		if nil != e {
+			t.Fatalf("Unable to create bucket: %v", e)
+		}
+		e = store.Set(ctx, tname, []byte("a"), []byte("v"))
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		got, e := store.Get(ctx, renamed, []byte("a"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, got, []byte("w"))
+	})
+
+	t.Run("del", func(t *testing.T) {
+		e := store.DelBucket(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to remove bucket: %v", e)
+		}
+	})
+}
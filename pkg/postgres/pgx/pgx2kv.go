@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 
 	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
 )
 
 func pgxSetTranBuilderNew(qgen QueryGenerator) func(t pgx.Tx) s2k.Set {
@@ -22,13 +23,27 @@ func pgxSetTranBuilderNew(qgen QueryGenerator) func(t pgx.Tx) s2k.Set {
 				return e
 			}
 			_, e = t.Exec(ctx, q, key, val)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
 
 type bufQueryGen func(bucket string) func(buf *strings.Builder) (query string, e error)
 
+// bufQueryGeneratorNew creates bufQueryGen which writes the query of g to the buffer.
+func bufQueryGeneratorNew(g QueryGenerator) bufQueryGen {
+	return func(bucket string) func(buf *strings.Builder) (query string, e error) {
+		return func(buf *strings.Builder) (query string, e error) {
+			query, e = g(bucket)
+			if nil != e {
+				return "", e
+			}
+			buf.WriteString(query)
+			return buf.String(), nil
+		}
+	}
+}
+
 var queryStrPool = sync.Pool{
 	New: func() any {
 		return new(strings.Builder)
@@ -60,7 +75,7 @@ func pgxBatchUpsertBuilderNew(qgen bufQueryGen) func(t pgx.Tx) s2k.SetBatch {
 			for i := 0; i < l; i++ {
 				_, e := results.Exec()
 				if nil != e {
-					return e
+					return ErrorConvert(e)
 				}
 			}
 
@@ -81,20 +96,20 @@ func pgxSetTranBuilderSingleNew(query builtQuery) func(t pgx.Tx) s2k.Set2Bucket
 				return query.e
 			}
 			_, e := t.Exec(ctx, query.query, key, val)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
 
-func pgxBucketAddNew(qgen QueryGenerator) func(p *pgxpool.Pool) s2k.AddBucket {
-	return func(p *pgxpool.Pool) s2k.AddBucket {
+func pgxBucketAddBuilder(qgen QueryGenerator) func(p pgxQuerier) s2k.AddBucket {
+	return func(p pgxQuerier) s2k.AddBucket {
 		return func(ctx context.Context, bucket string) error {
 			q, e := qgen(bucket)
 			if nil != e {
 				return e
 			}
 			_, e = p.Exec(ctx, q)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
@@ -107,7 +122,7 @@ func pgxLogAddNew(qgen QueryGenerator) func(p *pgxpool.Pool) s2k.AddLog {
 				return e
 			}
 			_, e = p.Exec(ctx, q)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
@@ -119,20 +134,20 @@ func pgxLogInsertNew(query builtQuery) func(p *pgxpool.Pool) s2k.InsLog {
 				return query.e
 			}
 			_, e := p.Exec(ctx, query.query, lg)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
 
-func pgxBucketDelNew(qgen QueryGenerator) func(p *pgxpool.Pool) s2k.DelBucket {
-	return func(p *pgxpool.Pool) s2k.DelBucket {
+func pgxBucketDelBuilder(qgen QueryGenerator) func(p pgxQuerier) s2k.DelBucket {
+	return func(p pgxQuerier) s2k.DelBucket {
 		return func(ctx context.Context, bucket string) error {
 			q, e := qgen(bucket)
 			if nil != e {
 				return e
 			}
 			_, e = p.Exec(ctx, q)
-			return e
+			return ErrorConvert(e)
 		}
 	}
 }
@@ -202,46 +217,18 @@ var pgxBatchUpsertNew func(qgen bufQueryGen) func(*pgxpool.Pool) s2k.SetBatch =
 type tableValidator func(tableName string) error
 type QueryGenerator func(bucketName string) (query string, e error)
 
-func bucket2queryNew(qgen QueryGenerator) func(bucketName string) builtQuery {
-	return func(bucketName string) builtQuery {
-		return qgen.build(bucketName)
-	}
-}
-
 func (q QueryGenerator) build(bucketName string) (b builtQuery) {
 	b.query, b.e = q(bucketName)
 	return
 }
 
-func queryGeneratorNew(v tableValidator, g QueryGenerator) QueryGenerator {
-	return func(bucketName string) (query string, e error) {
-		e = v(bucketName)
-		if nil != e {
-			return "", e
-		}
-		return g(bucketName)
-	}
-}
-
-func bufQueryGeneratorNew(v tableValidator, g bufQueryGen) bufQueryGen {
-	return func(bucketName string) func(buf *strings.Builder) (query string, e error) {
-		e := v(bucketName)
-		if nil != e {
-			return func(_ *strings.Builder) (string, error) {
-				return "", e
-			}
-		}
-		return g(bucketName)
-	}
-}
-
 func regexTableValidatorNew(re *regexp.Regexp) tableValidator {
 	return func(tableName string) error {
 		found := re.MatchString(tableName)
 		if found {
 			return nil
 		}
-		return fmt.Errorf("Invalid table name: %s", tableName)
+		return fmt.Errorf("Invalid table name(%s): %w", tableName, s2k.ErrInvalidBucket)
 	}
 }
 
@@ -257,111 +244,63 @@ func templateQueryGeneratorNew(name string) func(t *template.Template) QueryGene
 	return func(t *template.Template) QueryGenerator {
 		return func(bucketName string) (query string, e error) {
 			var buf strings.Builder
-			e = t.ExecuteTemplate(&buf, "root", map[string]string{"tableName": bucketName})
+			e = t.ExecuteTemplate(&buf, "root", bucketData(bucketName))
 			return buf.String(), e
 		}
 	}
 }
 
-func bufTemplateQueryGeneratorNew(name string) func(t *template.Template) bufQueryGen {
-	return func(t *template.Template) bufQueryGen {
-		return func(bucketName string) func(buf *strings.Builder) (query string, e error) {
-			return func(buf *strings.Builder) (query string, e error) {
-				e = t.ExecuteTemplate(buf, "root", map[string]string{"tableName": bucketName})
-				return buf.String(), e
-			}
-		}
-	}
-}
-
 var strQueryGeneratorNewMust func(s string) QueryGenerator = s2k.Compose(
 	str2templateMust("root"),
 	templateQueryGeneratorNew("root"),
 )
 
-var bufQueryGeneratorNewMust func(s string) bufQueryGen = s2k.Compose(
-	str2templateMust("root"),
-	bufTemplateQueryGeneratorNew("root"),
-)
-
-var pgTableValidator tableValidator = patTableValidatorNewMust(`^[a-z][a-z0-9_]{0,58}$`)
-
-const upsertQuery = `
-	INSERT INTO {{.tableName}} AS alias_t
-	VALUES($1, $2)
-	ON CONFLICT ON CONSTRAINT {{.tableName}}_pkc
-	DO UPDATE SET val=EXCLUDED.val
-	WHERE alias_t.val <> EXCLUDED.val
-`
-
-var pgUpsertGenerator QueryGenerator = strQueryGeneratorNewMust(upsertQuery)
-var pgBufUpsertGenerator bufQueryGen = bufQueryGeneratorNewMust(upsertQuery)
-
-var pgSetQueryGenerator QueryGenerator = queryGeneratorNew(
-	pgTableValidator,
-	pgUpsertGenerator,
-)
+// bucket: [namespace.]table
+var pgTableValidator tableValidator = patTableValidatorNewMust(`^([a-z][a-z0-9_]{0,58}\.)?[a-z][a-z0-9_]{0,58}$`)
+var pgNamespaceValidator tableValidator = patTableValidatorNewMust(`^[a-z][a-z0-9_]{0,58}$`)
 
-var pgBufSetQueryGenerator bufQueryGen = bufQueryGeneratorNew(
-	pgTableValidator,
-	pgBufUpsertGenerator,
-)
+func bucketData(bucket string) map[string]string {
+	tableName := pg.BucketTable(bucket)
+	return map[string]string{
+		"tableName": tableName,
+		"pkcName":   pg.BucketPkc(bucket),
+		"tableLit":  pg.QuoteLiteral(tableName),
+	}
+}
 
-var pgBulkAddQueryGenerator QueryGenerator = queryGeneratorNew(
-	pgTableValidator,
-	strQueryGeneratorNewMust(`
-		CREATE TABLE IF NOT EXISTS {{.tableName}} (
-		  key BYTEA,
-		  val BYTEA,
-		  CONSTRAINT {{.tableName}}_pkc PRIMARY KEY(key)
-		)
-	`),
-)
+var pgAddLogRawGenerator QueryGenerator = strQueryGeneratorNewMust(`
+	CREATE TABLE IF NOT EXISTS {{.tableName}} (
+		id BIGSERIAL,
+		lg BYTEA,
+		CONSTRAINT {{.pkcName}} PRIMARY KEY(id)
+	)
+`)
 
-var pgAddLogQueryGenerator QueryGenerator = queryGeneratorNew(
-	pgTableValidator,
-	strQueryGeneratorNewMust(`
-		CREATE TABLE IF NOT EXISTS {{.tableName}} (
-			id BIGSERIAL,
-			lg BYTEA,
-			CONSTRAINT {{.tableName}}_pkc PRIMARY KEY(id)
-		)
-	`),
-)
+// pgLogLockNamespace is the first key of the advisory locks taken by this package("s2k").
+const pgLogLockNamespace int32 = 0x0073326b
 
-var pgLogInsertQueryGenerator QueryGenerator = queryGeneratorNew(
-	pgTableValidator,
-	strQueryGeneratorNewMust(`
-		INSERT INTO {{.tableName}} (lg)
-		VALUES($1)
-	`),
-)
+// pgLogLockQuery serializes appends to the log until commit(keys: pgLogLockNamespace, OID of the log).
+// Ids(taken by appends) become visible in order; readers never skip ids committed late.
+var pgLogLockQuery string = fmt.Sprintf(`
+	WITH locked AS (
+	  SELECT PG_ADVISORY_XACT_LOCK(%d, ({{.tableLit}}::REGCLASS::OID::BIGINT - 2147483648)::INTEGER)
+	)
+`, pgLogLockNamespace)
 
-var pgBulkDelQueryGenerator QueryGenerator = queryGeneratorNew(
-	pgTableValidator,
-	strQueryGeneratorNewMust(`
-		DROP TABLE IF EXISTS {{.tableName}}
-	`),
-)
+var pgLogInsertRawGenerator QueryGenerator = strQueryGeneratorNewMust(pgLogLockQuery + `
+	INSERT INTO {{.tableName}} (lg)
+	SELECT $1::BYTEA FROM locked
+`)
 
-var PgxBulkSetNew func(p *pgxpool.Pool) s2k.SetMany = pgxBulkSetNew(pgSetQueryGenerator)
-var PgxAddBucketNew func(p *pgxpool.Pool) s2k.AddBucket = pgxBucketAddNew(pgBulkAddQueryGenerator)
-var PgxDelBucketNew func(p *pgxpool.Pool) s2k.DelBucket = pgxBucketDelNew(pgBulkDelQueryGenerator)
-var PgxAddLogNew func(p *pgxpool.Pool) s2k.AddLog = pgxLogAddNew(pgAddLogQueryGenerator)
+var PgxBulkSetNew func(p *pgxpool.Pool) s2k.SetMany = pgxDefaultMapping.BulkSet()
+var PgxAddBucketNew func(p *pgxpool.Pool) s2k.AddBucket = pool2querier(pgxBucketAddBuilder(pgxDefaultMapping.gen.AddBucket))
+var PgxDelBucketNew func(p *pgxpool.Pool) s2k.DelBucket = pool2querier(pgxBucketDelBuilder(pgxDefaultMapping.gen.DelBucket))
+var PgxAddLogNew func(p *pgxpool.Pool) s2k.AddLog = pgxDefaultMapping.AddLog()
 
-var PgxBatchUpsertNew func(p *pgxpool.Pool) s2k.SetBatch = pgxBatchUpsertNew(pgBufSetQueryGenerator)
+var PgxBatchUpsertNew func(p *pgxpool.Pool) s2k.SetBatch = pgxDefaultMapping.BatchUpsert()
 
-var PgxLogInsBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.InsLog = s2k.Compose(
-	bucket2queryNew(pgLogInsertQueryGenerator),
-	pgxLogInsertNew,
-)
+var PgxLogInsBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.InsLog = pgxDefaultMapping.LogIns
 
-var PgxBulkSetSingleBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.SetMany2Bucket = s2k.Compose(
-	bucket2queryNew(pgSetQueryGenerator),
-	pgxBulkSetSingleNew,
-)
+var PgxBulkSetSingleBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.SetMany2Bucket = pgxDefaultMapping.BulkSetSingle
 
-var PgxPairs2BucketSingleBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.Pairs2Bucket = s2k.Compose(
-	bucket2queryNew(pgSetQueryGenerator),
-	pgxPairs2BucketSingleNew,
-)
+var PgxPairs2BucketSingleBuilder func(bucketName string) func(p *pgxpool.Pool) s2k.Pairs2Bucket = pgxDefaultMapping.Pairs2BucketSingle
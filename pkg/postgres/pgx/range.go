@@ -0,0 +1,57 @@
+package pgx2kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+type rangeQueryGen func(bucketName string, r s2k.Range) (query string, e error)
+
+func pgxQueryCbNew(q pgxQuerier) s2k.QueryCb {
+	return func(ctx context.Context, cb s2k.RecordConsumer, query string, args ...any) error {
+		rows, e := q.Query(ctx, query, args...)
+		if nil != e {
+			return fmt.Errorf("Unable to get rows: %w", ErrorConvert(e))
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			e = cb(rows)
+			if nil != e {
+				return fmt.Errorf("Unable to process row: %w", e)
+			}
+		}
+		return ErrorConvert(rows.Err())
+	}
+}
+
+func pgxLstRangeBuilder(qgen rangeQueryGen) func(q pgxQuerier) s2k.LstRange {
+	return func(q pgxQuerier) s2k.LstRange {
+		var qcb s2k.QueryCb = pgxQueryCbNew(q)
+		return func(ctx context.Context, bucket string, r s2k.Range, cb func(key []byte) error) error {
+			q, e := qgen(bucket, r)
+			if nil != e {
+				return e
+			}
+			return qcb(
+				ctx,
+				func(row s2k.Record) error {
+					var key []byte
+					e := row.Scan(&key)
+					if nil != e {
+						return e
+					}
+					return cb(key)
+				},
+				q,
+				r.Args()...,
+			)
+		}
+	}
+}
+
+var PgxLstRangeNew func(p *pgxpool.Pool) s2k.LstRange = pgxDefaultMapping.LstRange()
@@ -0,0 +1,109 @@
+package pgx2kv
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestLstRangeQueryGenerator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid table name", func(t *testing.T) {
+		t.Parallel()
+		_, e := pgxDefaultMapping.gen.LstRange("0table", s2k.Range{})
+		if nil == e {
+			t.Errorf("Must reject invalid table name")
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		t.Parallel()
+		q, e := pgxDefaultMapping.gen.LstRange("t0", s2k.Range{Prefix: []byte("a"), Limit: 3, Reverse: true})
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		expected := `
+			SELECT key FROM "t0"
+			WHERE $1 <= key AND key < $2
+			ORDER BY key DESC
+			LIMIT $3
+		`
+		tq := strings.ReplaceAll(strings.TrimSpace(q), "	", "")
+		te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+		if tq != te {
+			t.Errorf("Unexpected value.\n")
+			t.Errorf("Expected: %s\n", te)
+			t.Errorf("Got: %s\n", tq)
+		}
+	})
+}
+
+func TestLstRange(t *testing.T) {
+	t.Parallel()
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var lr s2k.LstRange = PgxLstRangeNew(p)
+	var ab s2k.AddBucket = PgxAddBucketNew(p)
+	var sm s2k.SetMany = PgxBulkSetNew(p)
+
+	tname := "test_lst_range"
+
+	e = ab(context.Background(), tname)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+
+	e = sm(context.Background(), tname, []s2k.Pair{
+		{Key: []byte("a0"), Val: []byte("v")},
+		{Key: []byte("a1"), Val: []byte("v")},
+		{Key: []byte("a2"), Val: []byte("v")},
+		{Key: []byte("b0"), Val: []byte("v")},
+	})
+	if nil != e {
+		t.Fatalf("Unable to set: %v", e)
+	}
+
+	pat := []struct {
+		r        s2k.Range
+		n        string
+		expected []string
+	}{
+		{r: s2k.Range{Prefix: []byte("a")}, n: "prefix", expected: []string{"a0", "a1", "a2"}},
+		{r: s2k.Range{Start: []byte("a1"), End: []byte("b0")}, n: "start/end", expected: []string{"a1", "a2"}},
+		{r: s2k.Range{Limit: 2, Reverse: true}, n: "reverse limit", expected: []string{"b0", "a2"}},
+	}
+
+	for _, pt := range pat {
+		pt := pt
+		t.Run(pt.n, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			e := lr(context.Background(), tname, pt.r, func(key []byte) error {
+				got = append(got, string(key))
+				return nil
+			})
+			if nil != e {
+				t.Errorf("Unable to list: %v", e)
+			}
+			if strings.Join(got, ",") != strings.Join(pt.expected, ",") {
+				t.Errorf("Unexpected keys: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package pgx2kv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
+)
+
+var pgAddLogWithTimeRawGenerator QueryGenerator = strQueryGeneratorNewMust(`
+	CREATE TABLE IF NOT EXISTS {{.tableName}} (
+		id BIGSERIAL,
+		lg BYTEA,
+		created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CLOCK_TIMESTAMP(),
+		CONSTRAINT {{.pkcName}} PRIMARY KEY(id)
+	)
+`)
+
+type retentionQueryGen func(bucket string, r s2k.Retention) (query string, e error)
+
+// retentionData creates conditions with placeholders in the same order as retentionArgs
+func retentionData(bucket string, r s2k.Retention) map[string]string {
+	var tableName string = pg.BucketTable(bucket)
+	var conds []string
+	i := 2
+	if 0 < r.MaxAge {
+		conds = append(conds, fmt.Sprintf("created_at < CLOCK_TIMESTAMP() - $%d::BIGINT * INTERVAL '1 microsecond'", i))
+		i += 1
+	}
+	if 0 < r.MaxRows {
+		conds = append(conds, fmt.Sprintf(
+			"id < (SELECT MIN(id) FROM (SELECT id FROM %s ORDER BY id DESC LIMIT $%d) AS latest)",
+			tableName,
+			i,
+		))
+		i += 1
+	}
+	if r.BelowCommitted {
+		conds = append(conds, fmt.Sprintf("id <= (SELECT MIN(id) FROM %s)", pg.BucketTable(bucket2offset(bucket))))
+	}
+	return map[string]string{
+		"tableName": tableName,
+		"where":     strings.Join(conds, " OR "),
+	}
+}
+
+func retentionArgs(r s2k.Retention, limit int64) []any {
+	var args []any = []any{limit}
+	if 0 < r.MaxAge {
+		args = append(args, r.MaxAge.Microseconds())
+	}
+	if 0 < r.MaxRows {
+		args = append(args, r.MaxRows)
+	}
+	return args
+}
+
+// retentionGenerator creates a generator which uses the mapped log(and its offset table).
+func (x PgxMapping) retentionGenerator(s string) retentionQueryGen {
+	t := str2templateMust("root")(s)
+	return func(bucket string, r s2k.Retention) (query string, e error) {
+		e = r.Validate()
+		if nil != e {
+			return "", e
+		}
+		table, e := x.resolve(bucket)
+		if nil != e {
+			return "", e
+		}
+		if r.BelowCommitted {
+			e = x.validator(bucket2offset(table))
+			if nil != e {
+				return "", e
+			}
+			e = pg.CheckIdentifiers(bucket2offset(table))
+			if nil != e {
+				return "", e
+			}
+		}
+		var buf strings.Builder
+		e = t.ExecuteTemplate(&buf, "root", retentionData(table, r))
+		return buf.String(), e
+	}
+}
+
+const pgCompactQuery = `
+	WITH deleted AS (
+	  DELETE FROM {{.tableName}}
+	  WHERE id = ANY(ARRAY(
+	    SELECT id FROM {{.tableName}}
+	    WHERE {{.where}}
+	    ORDER BY id
+	    LIMIT $1
+	  ))
+	  RETURNING 1
+	)
+	SELECT COUNT(*) FROM deleted
+`
+
+func pgxCompactBuilder(qgen retentionQueryGen) func(q pgxQuerier) s2k.Compact {
+	return func(q pgxQuerier) s2k.Compact {
+		return func(ctx context.Context, bucket string, r s2k.Retention, limit int64) (deleted int64, e error) {
+			query, e := qgen(bucket, r)
+			if nil != e {
+				return 0, e
+			}
+			e = q.QueryRow(ctx, query, retentionArgs(r, limit)...).Scan(&deleted)
+			return deleted, ErrorConvert(e)
+		}
+	}
+}
+
+// PgxAddLogWithTimeNew creates AddLog which records insertion timestamps(required by Retention.MaxAge).
+var PgxAddLogWithTimeNew func(p *pgxpool.Pool) s2k.AddLog = pgxDefaultMapping.AddLogWithTime()
+
+// PgxCompactNew creates Compact; Retention.BelowCommitted requires the offset table(PgxAddLogWithOffsetNew).
+var PgxCompactNew func(p *pgxpool.Pool) s2k.Compact = pgxDefaultMapping.Compact()
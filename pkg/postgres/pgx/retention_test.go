@@ -0,0 +1,159 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+
+	t.Run("query", func(t *testing.T) {
+		t.Parallel()
+
+		r := s2k.Retention{MaxAge: time.Second, MaxRows: 10, BelowCommitted: true}
+		q, e := pgxDefaultMapping.retentionGenerator(pgCompactQuery)("l0", r)
+		if nil != e {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+		for _, expected := range []string{
+			`$2::BIGINT * INTERVAL`,
+			`ORDER BY id DESC LIMIT $3`,
+			`(SELECT MIN(id) FROM "l0_ofs")`,
+		} {
+			if !strings.Contains(q, expected) {
+				t.Errorf("Query must contain %s: %s", expected, q)
+			}
+		}
+		args := retentionArgs(r, 100)
+		if "[100 1000000 10]" != fmt.Sprint(args) {
+			t.Errorf("Unexpected args: %v", args)
+		}
+
+		q, e = pgxDefaultMapping.retentionGenerator(pgCompactQuery)("l0", s2k.Retention{MaxRows: 10})
+		if nil != e {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+		if !strings.Contains(q, `LIMIT $2`) || strings.Contains(q, `l0_ofs`) {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, e := pgxDefaultMapping.retentionGenerator(pgCompactQuery)("l0", s2k.Retention{})
+		if nil == e {
+			t.Errorf("Must reject empty retention")
+		}
+
+		_, e = pgxDefaultMapping.retentionGenerator(pgCompactQuery)("0invalid", s2k.Retention{MaxRows: 1})
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+
+		// offset table name too long
+		var x PgxMapping = PgxMappingNew(func(_ string) error { return nil }, s2k.BucketMapperIdentity, "")
+		_, e = x.retentionGenerator(pgCompactQuery)(strings.Repeat("l", 57), s2k.Retention{BelowCommitted: true})
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	lname := "test_log_compact"
+	bg := context.Background()
+
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+	cleanup := func() {
+		_ = db(bg, lname)
+		_ = db(bg, bucket2offset(lname))
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	e = PgxAddLogWithTimeNew(p)(bg, lname)
+	if nil != e {
+		t.Fatalf("Unable to create log: %v", e)
+	}
+	e = PgxAddLogWithOffsetNew(p)(bg, lname)
+	if nil != e {
+		t.Fatalf("Unable to create offset table: %v", e)
+	}
+
+	var ins s2k.InsLog = PgxLogInsBuilder(lname)(p)
+	for i := 0; i < 10; i++ {
+		e := ins(bg, []byte(fmt.Sprintf("lg%d", i)))
+		if nil != e {
+			t.Fatalf("Unable to insert log: %v", e)
+		}
+	}
+
+	var compact s2k.Compact = PgxCompactNew(p)
+	var r s2k.LogRead = PgxLogReadNew(p)
+	firstId := func() int64 {
+		records, e := r(bg, lname, 0, 1)
+		if nil != e || 1 != len(records) {
+			t.Fatalf("Unable to read log: %v, %v", records, e)
+		}
+		return records[0].Id
+	}
+	start := firstId()
+
+	// non parallel
+	t.Run("below committed", func(t *testing.T) {
+		deleted, e := compact(bg, lname, s2k.Retention{BelowCommitted: true}, 100)
+		if nil != e || 0 != deleted {
+			t.Errorf("Must keep logs without consumers: %v, %v", deleted, e)
+		}
+
+		_ = PgxOffsetCommitNew(p)(bg, lname, "g0", start+2)
+		_ = PgxOffsetCommitNew(p)(bg, lname, "g1", start+1)
+		deleted, e = compact(bg, lname, s2k.Retention{BelowCommitted: true}, 100)
+		if nil != e || 2 != deleted {
+			t.Errorf("Unexpected deleted: %v, %v", deleted, e)
+		}
+	})
+
+	t.Run("max rows", func(t *testing.T) {
+		sweep := s2k.CompactSweepNew(compact, s2k.Retention{MaxRows: 5})
+		total, e := s2k.SweepAll(bg, sweep, lname, 2)
+		if nil != e || 3 != total {
+			t.Errorf("Unexpected deleted: %v, %v", total, e)
+		}
+		if start+5 != firstId() {
+			t.Errorf("Unexpected first id: %v", firstId())
+		}
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		deleted, e := compact(bg, lname, s2k.Retention{MaxAge: time.Hour}, 100)
+		if nil != e || 0 != deleted {
+			t.Errorf("Must keep new logs: %v, %v", deleted, e)
+		}
+		time.Sleep(10 * time.Millisecond)
+		deleted, e = compact(bg, lname, s2k.Retention{MaxAge: time.Millisecond}, 100)
+		if nil != e || 5 != deleted {
+			t.Errorf("Unexpected deleted: %v, %v", deleted, e)
+		}
+	})
+}
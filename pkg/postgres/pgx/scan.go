@@ -0,0 +1,35 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func pgxScanBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Scan {
+	return func(q pgxQuerier) s2k.Scan {
+		var qcb s2k.QueryCb = pgxQueryCbNew(q)
+		return func(ctx context.Context, bucket string, cb func(pair s2k.Pair) error) error {
+			q, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			return qcb(
+				ctx,
+				func(row s2k.Record) error {
+					var pair s2k.Pair
+					e := row.Scan(&pair.Key, &pair.Val)
+					if nil != e {
+						return e
+					}
+					return cb(pair)
+				},
+				q,
+			)
+		}
+	}
+}
+
+var PgxScanNew func(p *pgxpool.Pool) s2k.Scan = pgxDefaultMapping.Scan()
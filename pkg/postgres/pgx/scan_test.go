@@ -0,0 +1,74 @@
+package pgx2kv
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var sc s2k.Scan = PgxScanNew(p)
+	var ab s2k.AddBucket = PgxAddBucketNew(p)
+	var sm s2k.SetMany = PgxBulkSetNew(p)
+
+	t.Run("invalid table name", func(t *testing.T) {
+		t.Parallel()
+		e := sc(context.Background(), "0table", func(_ s2k.Pair) error { return nil })
+		if nil == e {
+			t.Errorf("Must reject invalid table name")
+		}
+	})
+
+	// non parallel
+	t.Run("ordered", func(t *testing.T) {
+		tname := "test_scan"
+
+		e := ab(context.Background(), tname)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+
+		pairs := []s2k.Pair{
+			{Key: []byte("k0"), Val: []byte("v0")},
+			{Key: []byte("k1"), Val: []byte("v1")},
+		}
+		e = sm(context.Background(), tname, pairs)
+		if nil != e {
+			t.Fatalf("Unable to set: %v", e)
+		}
+
+		var got []s2k.Pair
+		e = sc(context.Background(), tname, func(pair s2k.Pair) error {
+			got = append(got, pair)
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to scan: %v", e)
+		}
+
+		if len(pairs) != len(got) {
+			t.Fatalf("Unexpected number of pairs: %v", len(got))
+		}
+		for i, pair := range pairs {
+			checkBytes(t, got[i].Key, pair.Key)
+			checkBytes(t, got[i].Val, pair.Val)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package pgx2kv
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func pgxBucketStatsBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.BucketStats {
+	return func(q pgxQuerier) s2k.BucketStats {
+		return func(ctx context.Context, bucket string) (s s2k.Stats, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return s, e
+			}
+			e = q.QueryRow(ctx, query).Scan(&s.Rows, &s.EstimatedRows, &s.TotalBytes, &s.AvgValBytes)
+			return s, ErrorConvert(e)
+		}
+	}
+}
+
+var PgxBucketStatsNew func(p *pgxpool.Pool) s2k.BucketStats = pgxDefaultMapping.BucketStats()
@@ -0,0 +1,82 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestBucketStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var s s2k.BucketStats = pgxBucketStatsBuilder(pgxDefaultMapping.gen.BucketStats)(nil)
+		_, e := s(context.Background(), "0invalid")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	tname := "test_bucket_stats"
+
+	var store s2k.Store = PgxStoreNew(p)
+	var stats s2k.BucketStats = PgxBucketStatsNew(p)
+
+	_ = store.DelBucket(ctx, tname)
+	e = store.AddBucket(ctx, tname)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+
+	// non parallel
+	t.Run("empty", func(t *testing.T) {
+		s, e := stats(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to get stats: %v", e)
+		}
+		if 0 != s.Rows || 0 != s.AvgValBytes {
+			t.Errorf("Unexpected stats: %v", s)
+		}
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		_ = store.Set(ctx, tname, []byte("k0"), []byte("ab"))
+		_ = store.Set(ctx, tname, []byte("k1"), []byte("abcd"))
+		s, e := stats(ctx, tname)
+		if nil != e {
+			t.Errorf("Unable to get stats: %v", e)
+		}
+		if 2 != s.Rows || 3 != s.AvgValBytes {
+			t.Errorf("Unexpected stats: %v", s)
+		}
+		if s.TotalBytes < 1 {
+			t.Errorf("Unexpected size: %v", s.TotalBytes)
+		}
+	})
+
+	t.Run("missing bucket", func(t *testing.T) {
+		_, e := stats(ctx, "test_bucket_stats_missing")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
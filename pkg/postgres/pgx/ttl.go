@@ -0,0 +1,41 @@
+package pgx2kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func pgxSetWithTtlBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.SetWithTTL {
+	return func(q pgxQuerier) s2k.SetWithTTL {
+		return func(ctx context.Context, bucket string, key, val []byte, ttl time.Duration) error {
+			query, e := qgen(bucket)
+			if nil != e {
+				return e
+			}
+			_, e = q.Exec(ctx, query, key, val, ttl.Microseconds())
+			return ErrorConvert(e)
+		}
+	}
+}
+
+func pgxSweepBuilder(qgen QueryGenerator) func(q pgxQuerier) s2k.Sweep {
+	return func(q pgxQuerier) s2k.Sweep {
+		return func(ctx context.Context, bucket string, limit int64) (deleted int64, e error) {
+			query, e := qgen(bucket)
+			if nil != e {
+				return 0, e
+			}
+			e = q.QueryRow(ctx, query, limit).Scan(&deleted)
+			return deleted, ErrorConvert(e)
+		}
+	}
+}
+
+var PgxTtlGetNew func(p *pgxpool.Pool) s2k.Get = pgxDefaultMapping.TtlGet()
+var PgxSetWithTTLNew func(p *pgxpool.Pool) s2k.SetWithTTL = pgxDefaultMapping.SetWithTTL()
+var PgxSweepNew func(p *pgxpool.Pool) s2k.Sweep = pgxDefaultMapping.Sweep()
+var PgxAddTtlBucketNew func(p *pgxpool.Pool) s2k.AddBucket = pgxDefaultMapping.AddTtlBucket()
@@ -0,0 +1,104 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestTtl(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		var s s2k.SetWithTTL = pgxSetWithTtlBuilder(pgxDefaultMapping.gen.TtlSet)(nil)
+		e := s(context.Background(), "0invalid", nil, nil, time.Second)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("expires_at index", func(t *testing.T) {
+		t.Parallel()
+
+		q, e := pgxDefaultMapping.gen.AddTtlBucket("ns.t0")
+		if nil != e {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+		expected := `CREATE INDEX IF NOT EXISTS "t0_exp" ON "ns"."t0"(expires_at)`
+		if !strings.Contains(q, expected) || !strings.Contains(q, "WHERE expires_at IS NOT NULL") {
+			t.Errorf("Unexpected query: %s", q)
+		}
+	})
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	ctx := context.Background()
+	tname := "test_ttl"
+
+	var ab s2k.AddBucket = PgxAddTtlBucketNew(p)
+	var db s2k.DelBucket = PgxDelBucketNew(p)
+	var set s2k.SetWithTTL = PgxSetWithTTLNew(p)
+	var get s2k.Get = PgxTtlGetNew(p)
+	var sweep s2k.Sweep = PgxSweepNew(p)
+
+	_ = db(ctx, tname)
+	e = ab(ctx, tname)
+	if nil != e {
+		t.Fatalf("Unable to create table: %v", e)
+	}
+
+	// non parallel
+	t.Run("set", func(t *testing.T) {
+		for _, k := range []string{"k0", "k1", "k2"} {
+			e := set(ctx, tname, []byte(k), []byte("v"), time.Microsecond)
+			if nil != e {
+				t.Errorf("Unable to set: %v", e)
+			}
+		}
+		e := set(ctx, tname, []byte("forever"), []byte("v"), 0)
+		if nil != e {
+			t.Errorf("Unable to set: %v", e)
+		}
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		_, e := get(ctx, tname, []byte("k0"))
+		if !errors.Is(e, s2k.ErrNotFound) {
+			t.Errorf("Expired key must not be found: %v", e)
+		}
+		v, e := get(ctx, tname, []byte("forever"))
+		if nil != e {
+			t.Errorf("Unable to get: %v", e)
+		}
+		checkBytes(t, v, []byte("v"))
+	})
+
+	t.Run("sweep", func(t *testing.T) {
+		total, e := s2k.SweepAll(ctx, sweep, tname, 2)
+		if nil != e {
+			t.Errorf("Unable to sweep: %v", e)
+		}
+		if 3 != total {
+			t.Errorf("Unexpected number of deleted rows: %v", total)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package pgx2kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	pgx_dbname := os.Getenv("ITEST_SQL2KEYVAL_PGX_DBNAME")
+	if len(pgx_dbname) < 1 {
+		t.Skip("skipping pgx test...")
+	}
+
+	p, e := pgxpool.Connect(context.Background(), "dbname="+pgx_dbname)
+	if nil != e {
+		t.Fatalf("Unable to connect to test db: %v", e)
+	}
+	t.Cleanup(p.Close)
+
+	var withTx s2k.WithTx = PgxWithTxNew(p)
+	var store s2k.Store = PgxStoreNew(p)
+	ctx := context.Background()
+
+	tnames := []string{"test_tx_1", "test_tx_2"}
+	for _, tn := range tnames {
+		_ = store.DelBucket(ctx, tn)
+		e := store.AddBucket(ctx, tn)
+		if nil != e {
+			t.Fatalf("Unable to create table: %v", e)
+		}
+	}
+
+	// non parallel
+	t.Run("rollback", func(t *testing.T) {
+		e := withTx(ctx, func(tx s2k.Store) error {
+			for _, tn := range tnames {
+				e := tx.Set(ctx, tn, []byte("k"), []byte("rollback"))
+				if nil != e {
+					return e
+				}
+			}
+			return fmt.Errorf("Must rollback")
+		})
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+		for _, tn := range tnames {
+			_, e := store.Get(ctx, tn, []byte("k"))
+			if !errors.Is(e, s2k.ErrNotFound) {
+				t.Errorf("Must be rolled back: %v", e)
+			}
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		e := withTx(ctx, func(tx s2k.Store) error {
+			for _, tn := range tnames {
+				e := tx.Set(ctx, tn, []byte("k"), []byte("commit"))
+				if nil != e {
+					return e
+				}
+			}
+			got, e := tx.Get(ctx, tnames[0], []byte("k"))
+			if nil != e {
+				return e
+			}
+			checkBytes(t, got, []byte("commit"))
+			return nil
+		})
+		if nil != e {
+			t.Errorf("Unable to commit: %v", e)
+		}
+		for _, tn := range tnames {
+			got, e := store.Get(ctx, tn, []byte("k"))
+			if nil != e {
+				t.Errorf("Unable to get: %v", e)
+			}
+			checkBytes(t, got, []byte("commit"))
+		}
+	})
+}
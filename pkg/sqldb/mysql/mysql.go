@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func init() {
+	qgen := newQueryGeneratorMust()
+	s2k.RegisterQueryGenerator("mysql", &qgen)
+	s2k.RegisterErrorConverter(errorConvert)
+}
+
+var errorNumber2sentinel = map[int64]error{
+	1062: s2k.ErrDuplicateKey,   // ER_DUP_ENTRY
+	1146: s2k.ErrBucketNotFound, // ER_NO_SUCH_TABLE
+}
+
+// errorConverterNew creates a converter which reads the error number of the errors defined in pkg.
+func errorConverterNew(pkg string) s2k.ErrorConverter {
+	return func(e error) error {
+		number, found := s2k.ErrorCode(e, pkg, "Number")
+		if !found {
+			return nil
+		}
+		return errorNumber2sentinel[number]
+	}
+}
+
+// errorConvert converts errors by the error number(*mysql.MySQLError of go-sql-driver/mysql).
+var errorConvert s2k.ErrorConverter = errorConverterNew("github.com/go-sql-driver/mysql")
+
+type validator func(bucketName string) error
+
+type queryGenerator struct {
+	tableChecker validator
+	tmpl         *template.Template
+}
+
+func newValidatorRegexpMust(pattern string) validator {
+	re := regexp.MustCompile(pattern)
+	return func(bucketName string) error {
+		return s2k.Bool2error(
+			re.MatchString(bucketName),
+			func() error {
+				return fmt.Errorf("Invalid bucket name(%s): %w", bucketName, s2k.ErrInvalidBucket)
+			},
+		)
+	}
+}
+
+func newQueryGeneratorMust() queryGenerator {
+	// 0:     first char: [a-z]
+	// 1-58:  identifier
+	// 59-62: _pkc(reserved)
+	// 63:    unused(max identifier length: 64)
+	tableChecker := newValidatorRegexpMust(`^[a-z][0-9a-z_]{0,58}$`)
+
+	// VARBINARY(3072): max index key length of InnoDB
+	tmpl := template.Must(template.New("root").Parse(`
+	  {{define "Get"}}
+		SELECT val FROM {{.tableName}}
+		WHERE {{.key}}=?
+		LIMIT 1
+	  {{end}}
+
+	  {{define "Lst"}}
+		SELECT {{.key}} FROM {{.tableName}}
+		ORDER BY {{.key}}
+	  {{end}}
+
+	  {{define "Del"}}
+		DELETE FROM {{.tableName}}
+		WHERE {{.key}}=?
+	  {{end}}
+
+	  {{define "Add"}}
+		INSERT INTO {{.tableName}}({{.key}}, val)
+		VALUES (?, ?)
+	  {{end}}
+
+	  {{define "Set"}}
+		INSERT INTO {{.tableName}}({{.key}}, val)
+		VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE val=VALUES(val)
+	  {{end}}
+
+	  {{define "BDel"}}
+		DROP TABLE IF EXISTS {{.tableName}}
+	  {{end}}
+
+	  {{define "BAdd"}}
+		CREATE TABLE IF NOT EXISTS {{.tableName}}(
+		  {{.key}} VARBINARY(3072),
+		  val LONGBLOB NOT NULL,
+		  CONSTRAINT {{.pkcName}} PRIMARY KEY({{.key}})
+		)
+	  {{end}}
+	`))
+	return queryGenerator{
+		tableChecker,
+		tmpl,
+	}
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (q *queryGenerator) generate(bucket string, name string) (query string, e error) {
+	e = q.tableChecker(bucket)
+	if nil != e {
+		return "", e
+	}
+
+	var buf strings.Builder
+	e = q.tmpl.ExecuteTemplate(&buf, name, map[string]string{
+		"tableName": quoteIdentifier(bucket),
+		"pkcName":   quoteIdentifier(bucket + "_pkc"),
+		"key":       quoteIdentifier("key"), // reserved word
+	})
+	query = buf.String()
+	return
+}
+
+func (q *queryGenerator) Get(bucket string) (query string, e error)  { return q.generate(bucket, "Get") }
+func (q *queryGenerator) Del(bucket string) (query string, e error)  { return q.generate(bucket, "Del") }
+func (q *queryGenerator) Add(bucket string) (query string, e error)  { return q.generate(bucket, "Add") }
+func (q *queryGenerator) Set(bucket string) (query string, e error)  { return q.generate(bucket, "Set") }
+func (q *queryGenerator) Lst(bucket string) (query string, e error)  { return q.generate(bucket, "Lst") }
+func (q *queryGenerator) DelBucket(b string) (query string, e error) { return q.generate(b, "BDel") }
+func (q *queryGenerator) AddBucket(b string) (query string, e error) { return q.generate(b, "BAdd") }
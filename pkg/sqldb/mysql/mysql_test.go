@@ -0,0 +1,179 @@
+package mysql
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func normalize(query string) string {
+	return strings.ReplaceAll(strings.TrimSpace(query), "	", "") + "\n"
+}
+
+func checkGolden(t *testing.T, name string, query string) {
+	golden := filepath.Join("testdata", name+".sql")
+	got := normalize(query)
+
+	if *update {
+		e := os.WriteFile(golden, []byte(got), 0644)
+		if nil != e {
+			t.Fatalf("Unable to update golden file: %v", e)
+		}
+	}
+
+	expected, e := os.ReadFile(golden)
+	if nil != e {
+		t.Fatalf("Unable to read golden file: %v", e)
+	}
+
+	if got != string(expected) {
+		t.Errorf("Unexpected value.\n")
+		t.Errorf("Expected: %s\n", expected)
+		t.Errorf("Got: %s\n", got)
+	}
+}
+
+func TestNewQueryGeneratorMust(t *testing.T) {
+	t.Parallel()
+
+	const tname = "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
+
+	t.Run("no panic", func(t *testing.T) {
+		t.Parallel()
+		newQueryGeneratorMust()
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		pat := []struct {
+			b string
+			n string
+		}{
+			{b: "", n: "empty bucket"},
+			{b: "0zero", n: "first char is number"},
+			{b: "t`; DROP TABLE t", n: "injection"},
+			{b: tname + "rstuv", n: "too long tablename"},
+		}
+
+		for _, p := range pat {
+			p := p
+			t.Run(p.n, func(t *testing.T) {
+				t.Parallel()
+				qgen := newQueryGeneratorMust()
+				_, e := qgen.Set(p.b)
+				if nil == e {
+					t.Errorf("Must reject invalid bucket: %s", p.b)
+				}
+			})
+		}
+	})
+
+	pat := []struct {
+		f func(q *queryGenerator) func(string) (string, error)
+		n string
+	}{
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.Get }, n: "Get"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.Lst }, n: "Lst"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.Del }, n: "Del"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.Add }, n: "Add"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.Set }, n: "Set"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.DelBucket }, n: "BDel"},
+		{f: func(q *queryGenerator) func(string) (string, error) { return q.AddBucket }, n: "BAdd"},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+			qgen := newQueryGeneratorMust()
+			query, e := p.f(&qgen)(tname)
+			if nil != e {
+				t.Errorf("Must accept 'short' tablename: %v", e)
+			}
+			checkGolden(t, p.n, query)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	t.Parallel()
+
+	var got string
+	var dummyExec s2k.Exec = func(_ context.Context, query string, _ ...any) error {
+		got = query
+		return nil
+	}
+
+	var setter s2k.Set = s2k.SetFactory("mysql")(dummyExec)
+	e := setter(context.Background(), "bucket0", []byte("k"), []byte("v"))
+	if nil != e {
+		t.Errorf("Unexpected error: %v", e)
+	}
+
+	expected := normalize("INSERT INTO `bucket0`(`key`, val)\nVALUES (?, ?)\nON DUPLICATE KEY UPDATE val=VALUES(val)")
+	if normalize(got) != expected {
+		t.Errorf("Unexpected value.\n")
+		t.Errorf("Expected: %s\n", expected)
+		t.Errorf("Got: %s\n", got)
+	}
+}
+
+// MySQLError has the same shape as *mysql.MySQLError of go-sql-driver/mysql.
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (m *MySQLError) Error() string { return fmt.Sprintf("Error %d: %s", m.Number, m.Message) }
+
+func TestErrorConvert(t *testing.T) {
+	t.Parallel()
+
+	pat := []struct {
+		number   uint16
+		sentinel error
+	}{
+		{number: 1062, sentinel: s2k.ErrDuplicateKey},
+		{number: 1146, sentinel: s2k.ErrBucketNotFound},
+	}
+
+	// the fake of *mysql.MySQLError is defined in this package
+	convert := errorConverterNew(reflect.TypeOf(MySQLError{}).PkgPath())
+
+	for _, p := range pat {
+		p := p
+		t.Run(fmt.Sprint(p.number), func(t *testing.T) {
+			t.Parallel()
+			sentinel := convert(fmt.Errorf("wrapped: %w", &MySQLError{Number: p.number}))
+			if p.sentinel != sentinel {
+				t.Errorf("Must be converted: %v", sentinel)
+			}
+		})
+	}
+
+	t.Run("unknown number", func(t *testing.T) {
+		t.Parallel()
+		if nil != convert(&MySQLError{Number: 1064}) {
+			t.Errorf("Must not be converted")
+		}
+	})
+
+	t.Run("other package", func(t *testing.T) {
+		t.Parallel()
+		native := &MySQLError{Number: 1062}
+		if error(native) != s2k.ErrorFromSqlState(native) {
+			t.Errorf("Must ignore errors not defined in go-sql-driver/mysql")
+		}
+	})
+}
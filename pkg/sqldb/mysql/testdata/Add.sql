@@ -0,0 +1,2 @@
+INSERT INTO `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`(`key`, val)
+VALUES (?, ?)
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`(
+  `key` VARBINARY(3072),
+  val LONGBLOB NOT NULL,
+  CONSTRAINT `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq_pkc` PRIMARY KEY(`key`)
+)
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`
@@ -0,0 +1,2 @@
+DELETE FROM `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`
+WHERE `key`=?
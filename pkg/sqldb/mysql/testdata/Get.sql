@@ -0,0 +1,3 @@
+SELECT val FROM `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`
+WHERE `key`=?
+LIMIT 1
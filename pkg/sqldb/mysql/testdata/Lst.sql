@@ -0,0 +1,2 @@
+SELECT `key` FROM `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`
+ORDER BY `key`
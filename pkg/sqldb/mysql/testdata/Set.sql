@@ -0,0 +1,3 @@
+INSERT INTO `t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq`(`key`, val)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE val=VALUES(val)
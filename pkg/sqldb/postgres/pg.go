@@ -14,31 +14,64 @@ func init() {
 	s2k.RegisterQueryGenerator("postgres", &qgen)
 }
 
-type validator func(bucketName string) error
+// Validator rejects invalid bucket/namespace names.
+type Validator func(name string) error
 
 type queryGenerator struct {
-	tableChecker validator
-	tmpl         *template.Template
+	tableChecker     Validator
+	namespaceChecker Validator
+	tmpl             *template.Template
+	mapper           s2k.BucketMapper
+	registry         string // table which records bucket -> table mapping(empty: no registry)
 }
 
-func newValidatorRegexpMust(pattern string) validator {
+func ValidatorRegexpNewMust(pattern string) Validator {
 	re := regexp.MustCompile(pattern)
 	return func(bucketName string) error {
 		return s2k.Bool2error(
 			re.MatchString(bucketName),
 			func() error {
-				return fmt.Errorf("Invalid bucket name: %s", bucketName)
+				return fmt.Errorf("Invalid bucket name(%s): %w", bucketName, s2k.ErrInvalidBucket)
 			},
 		)
 	}
 }
 
+// QueryGeneratorNew creates a generator which accepts names checked by the validators.
+//
+// Identifiers are always quoted; names longer than 59 bytes(63 - len("_pkc")) are rejected.
+// A bucket "namespace.table" is split at the first dot.
+// LstBucket lists tables with key/val columns and the "_pkc" constraint(other namespaces: "namespace.table").
+// Register it under a custom driver name by s2k.RegisterQueryGenerator.
+func QueryGeneratorNew(tableChecker, namespaceChecker Validator) s2k.QueryGenerator {
+	qgen := queryGeneratorNewMust(tableChecker, namespaceChecker)
+	return &qgen
+}
+
+// MappedQueryGeneratorNew creates a generator which uses the table mapped by m for each bucket.
+//
+// The validators check the mapped table names.
+// The registry table(created on demand) records the mapping;
+// LstBucket/HasBucket use the registry to get logical names(a missing registry has no buckets).
+func MappedQueryGeneratorNew(tableChecker, namespaceChecker Validator, m s2k.BucketMapper, registry string) s2k.QueryGenerator {
+	qgen := queryGeneratorNewMust(tableChecker, namespaceChecker)
+	qgen.mapper = m
+	qgen.registry = registry
+	return &qgen
+}
+
 func newQueryGeneratorMust() queryGenerator {
 	// 0:     first char: [a-z]
 	// 1-58:  identifier
 	// 59-62: _pkc(reserved)
 	// 63:    null char(reserved)
-	tableChecker := newValidatorRegexpMust(`^[a-z][0-9a-z_]{0,58}$`)
+	// optional namespace(schema): same rule, separated by a dot
+	tableChecker := ValidatorRegexpNewMust(`^([a-z][0-9a-z_]{0,58}\.)?[a-z][0-9a-z_]{0,58}$`)
+	namespaceChecker := ValidatorRegexpNewMust(`^[a-z][0-9a-z_]{0,58}$`)
+	return queryGeneratorNewMust(tableChecker, namespaceChecker)
+}
+
+func queryGeneratorNewMust(tableChecker, namespaceChecker Validator) queryGenerator {
 	tmpl := template.Must(template.New("root").Parse(`
 	  {{define "Get"}}
 		SELECT val FROM {{.tableName}}
@@ -46,11 +79,32 @@ func newQueryGeneratorMust() queryGenerator {
 		LIMIT 1
 	  {{end}}
 
+	  {{define "GetMany"}}
+		SELECT key, val FROM {{.tableName}}
+		WHERE key = ANY($1)
+	  {{end}}
+
 	  {{define "Lst"}}
 		SELECT key FROM {{.tableName}}
 		ORDER BY key
 	  {{end}}
 
+	  {{define "LstRange"}}
+		SELECT key FROM {{.tableName}}
+		{{- if .where}}
+		WHERE {{.where}}
+		{{- end}}
+		ORDER BY key {{.order}}
+		{{- if .limit}}
+		LIMIT {{.limit}}
+		{{- end}}
+	  {{end}}
+
+	  {{define "Scan"}}
+		SELECT key, val FROM {{.tableName}}
+		ORDER BY key
+	  {{end}}
+
 	  {{define "Del"}}
 		DELETE FROM {{.tableName}}
 		WHERE key=$1
@@ -64,45 +118,554 @@ func newQueryGeneratorMust() queryGenerator {
 	  {{define "Set"}}
 		INSERT INTO {{.tableName}} AS alias_insert (key, val)
 		VALUES ($1, $2)
-		ON CONFLICT ON CONSTRAINT {{.tableName}}_pkc
+		ON CONFLICT ON CONSTRAINT {{.pkcName}}
 		DO UPDATE SET val=EXCLUDED.val
 		WHERE alias_insert.val != EXCLUDED.val
 	  {{end}}
 
+	  {{define "CasUpdate"}}
+		UPDATE {{.tableName}}
+		SET val=$3
+		WHERE key=$1 AND val=$2
+		RETURNING key
+	  {{end}}
+
+	  {{define "CasInsert"}}
+		INSERT INTO {{.tableName}}(key, val)
+		VALUES ($1, $2)
+		ON CONFLICT ON CONSTRAINT {{.pkcName}}
+		DO NOTHING
+		RETURNING key
+	  {{end}}
+
+	  {{define "VGet"}}
+		SELECT val, ver FROM {{.tableName}}
+		WHERE key=$1
+		LIMIT 1
+	  {{end}}
+
+	  {{define "VUpdate"}}
+		UPDATE {{.tableName}}
+		SET val=$3, ver=ver+1
+		WHERE key=$1 AND ver=$2
+		RETURNING ver
+	  {{end}}
+
+	  {{define "VInsert"}}
+		INSERT INTO {{.tableName}}(key, val, ver)
+		VALUES ($1, $2, 1)
+		ON CONFLICT ON CONSTRAINT {{.pkcName}}
+		DO NOTHING
+		RETURNING ver
+	  {{end}}
+
+	  {{define "BAddV"}}
+		CREATE TABLE IF NOT EXISTS {{.tableName}}(
+		  key BYTEA,
+		  val BYTEA NOT NULL,
+		  ver BIGINT NOT NULL DEFAULT 1,
+		  CONSTRAINT {{.pkcName}} PRIMARY KEY(key)
+		){{template "Register" .}}
+	  {{end}}
+
+	  {{define "TGet"}}
+		SELECT val FROM {{.tableName}}
+		WHERE key=$1 AND (expires_at IS NULL OR CLOCK_TIMESTAMP() < expires_at)
+		LIMIT 1
+	  {{end}}
+
+	  {{define "TSet"}}
+		INSERT INTO {{.tableName}}(key, val, expires_at)
+		VALUES (
+		  $1,
+		  $2,
+		  CASE WHEN 0 < $3::BIGINT THEN CLOCK_TIMESTAMP() + $3::BIGINT * INTERVAL '1 microsecond' END
+		)
+		ON CONFLICT ON CONSTRAINT {{.pkcName}}
+		DO UPDATE SET val=EXCLUDED.val, expires_at=EXCLUDED.expires_at
+	  {{end}}
+
+	  {{define "Sweep"}}
+		WITH deleted AS (
+		  DELETE FROM {{.tableName}}
+		  WHERE ctid = ANY(ARRAY(
+		    SELECT ctid FROM {{.tableName}}
+		    WHERE expires_at <= CLOCK_TIMESTAMP()
+		    LIMIT $1
+		  ))
+		  RETURNING 1
+		)
+		SELECT COUNT(*) FROM deleted
+	  {{end}}
+
+	  {{define "BAddT"}}
+		CREATE TABLE IF NOT EXISTS {{.tableName}}(
+		  key BYTEA,
+		  val BYTEA NOT NULL,
+		  expires_at TIMESTAMP WITH TIME ZONE,
+		  CONSTRAINT {{.pkcName}} PRIMARY KEY(key)
+		);
+		CREATE INDEX IF NOT EXISTS {{.expName}} ON {{.tableName}}(expires_at)
+		WHERE expires_at IS NOT NULL{{template "Register" .}}
+	  {{end}}
+
+	  {{define "Incr"}}
+		INSERT INTO {{.tableName}} AS alias_insert (key, val)
+		VALUES ($1, INT8SEND($2::BIGINT))
+		ON CONFLICT ON CONSTRAINT {{.pkcName}}
+		DO UPDATE SET val=INT8SEND(('x' || ENCODE(alias_insert.val, 'hex'))::BIT(64)::BIGINT + $2::BIGINT)
+		WHERE OCTET_LENGTH(alias_insert.val) = 8
+		RETURNING ('x' || ENCODE(val, 'hex'))::BIT(64)::BIGINT
+	  {{end}}
+
+	  {{define "BLst"}}
+		{{- if .registry}}
+		SELECT bucket FROM {{.registry}}
+		ORDER BY bucket
+		{{- else}}
+		SELECT (CASE WHEN t.table_schema = CURRENT_SCHEMA() THEN '' ELSE t.table_schema || '.' END || t.table_name)::TEXT AS bucket
+		FROM information_schema.table_constraints t
+		WHERE t.constraint_type = 'PRIMARY KEY'
+		  AND t.constraint_name = t.table_name || '_pkc'
+		  AND t.table_schema NOT IN ('pg_catalog', 'information_schema')
+		  AND {{template "KvShape"}}
+		ORDER BY bucket
+		{{- end}}
+	  {{end}}
+
+	  {{define "KvShape" -}}
+		2 = (
+		    SELECT COUNT(*) FROM information_schema.columns c
+		    WHERE c.table_schema = t.table_schema AND c.table_name = t.table_name
+		      AND c.column_name IN ('key', 'val')
+		  )
+	  {{- end}}
+
+	  {{define "BHas"}}
+		{{- if .registry}}
+		SELECT EXISTS(
+		  SELECT 1 FROM {{.registry}}
+		  WHERE bucket={{.bucketLit}}
+		)
+		{{- else}}
+		SELECT EXISTS(
+		  SELECT 1 FROM information_schema.table_constraints t
+		  WHERE t.constraint_type = 'PRIMARY KEY'
+		    AND t.constraint_name = t.table_name || '_pkc'
+		    AND (
+		      (t.table_schema = CURRENT_SCHEMA() AND t.table_name::TEXT = {{.physicalLit}})
+		      OR t.table_schema || '.' || t.table_name = {{.physicalLit}}
+		    )
+		    AND {{template "KvShape"}}
+		)
+		{{- end}}
+	  {{end}}
+
+	  {{define "BStats"}}
+		SELECT
+		  COUNT(*),
+		  (
+		    SELECT (CASE WHEN MIN(c.reltuples) < 0 THEN -1 ELSE SUM(c.reltuples) END)::BIGINT
+		    FROM PG_PARTITION_TREE({{.tableLit}}::REGCLASS) t JOIN pg_class c ON c.oid = t.relid
+		    WHERE t.isleaf
+		  ),
+		  (SELECT SUM(PG_TOTAL_RELATION_SIZE(relid))::BIGINT FROM PG_PARTITION_TREE({{.tableLit}}::REGCLASS)),
+		  COALESCE(AVG(OCTET_LENGTH(val)), 0)::FLOAT8
+		FROM {{.tableName}}
+	  {{end}}
+
+	  {{define "BRename"}}
+		DO {{.bodyLit}};
+		ALTER TABLE {{.src}} RENAME TO {{.dstName}};
+		ALTER TABLE {{.dst}} RENAME CONSTRAINT {{.srcPkc}} TO {{.dstPkc}};
+		ALTER INDEX IF EXISTS {{.srcExp}} RENAME TO {{.dstExp}}
+		{{- if .registry}};
+		{{template "Registry" .}};
+		DELETE FROM {{.registry}} WHERE bucket={{.srcBucketLit}};
+		INSERT INTO {{.registry}}(bucket, tbl) VALUES ({{.bucketLit}}, {{.physicalLit}})
+		{{- end}}
+	  {{end}}
+
+	  {{define "BRenameP" -}}
+		DECLARE
+		  r RECORD;
+		BEGIN
+		  FOR r IN
+		    SELECT c.oid::REGCLASS AS child, {{.dstNameLit}} || SUBSTR(c.relname, LENGTH({{.srcNameLit}}) + 1) AS name
+		    FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		    WHERE i.inhparent = {{.srcLit}}::REGCLASS
+		      AND LEFT(c.relname, LENGTH({{.srcNameLit}})) = {{.srcNameLit}}
+		      AND SUBSTR(c.relname, LENGTH({{.srcNameLit}}) + 1) ~ '^_p[0-9]+$'
+		  LOOP
+		    IF 63 < OCTET_LENGTH(r.name) THEN
+		      RAISE EXCEPTION USING ERRCODE = 'name_too_long', MESSAGE = 'Too long partition name: ' || r.name;
+		    END IF;
+		    EXECUTE FORMAT('ALTER TABLE %s RENAME TO %I', r.child, r.name);
+		  END LOOP;
+		END
+	  {{- end}}
+
+	  {{define "BCopy"}}
+		CREATE TABLE {{.dst}} (LIKE {{.src}} INCLUDING ALL EXCLUDING INDEXES);
+		ALTER TABLE {{.dst}} ADD CONSTRAINT {{.dstPkc}} PRIMARY KEY(key);
+		INSERT INTO {{.dst}} SELECT * FROM {{.src}};
+		DO {{.bodyLit}}{{template "Register" .}}
+	  {{end}}
+
+	  {{define "BCopyP" -}}
+		BEGIN
+		  IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = {{.srcLit}}::REGCLASS) THEN
+		    RAISE EXCEPTION USING ERRCODE = 'wrong_object_type', MESSAGE = 'Unable to copy partitioned table: ' || {{.srcLit}};
+		  END IF;
+		  IF TO_REGCLASS({{.srcExpLit}}) IS NOT NULL THEN
+		    EXECUTE FORMAT('CREATE INDEX %s ON %s(expires_at) WHERE expires_at IS NOT NULL', {{.dstExpLit}}, {{.dstLit}});
+		  END IF;
+		END
+	  {{- end}}
+
+	  {{define "BTruncate"}}
+		TRUNCATE TABLE {{.tableName}}
+	  {{end}}
+
+	  {{define "NAdd"}}
+		CREATE SCHEMA IF NOT EXISTS {{.namespace}}
+	  {{end}}
+
+	  {{define "NDel"}}
+		DROP SCHEMA IF EXISTS {{.namespace}}
+	  {{end}}
+
 	  {{define "BDel"}}
 		DROP TABLE IF EXISTS {{.tableName}}
+		{{- if .registry}};
+		{{template "Registry" .}};
+		DELETE FROM {{.registry}} WHERE bucket={{.bucketLit}}
+		{{- end}}
 	  {{end}}
 
 	  {{define "BAdd"}}
 		CREATE TABLE IF NOT EXISTS {{.tableName}}(
 		  key BYTEA,
 		  val BYTEA NOT NULL,
-		  CONSTRAINT {{.tableName}}_pkc PRIMARY KEY(key)
-		)
+		  CONSTRAINT {{.pkcName}} PRIMARY KEY(key)
+		){{template "Register" .}}
+	  {{end}}
+
+	  {{define "BAddP"}}
+		CREATE TABLE IF NOT EXISTS {{.tableName}}(
+		  key BYTEA,
+		  val BYTEA NOT NULL,
+		  CONSTRAINT {{.pkcName}} PRIMARY KEY(key)
+		) PARTITION BY HASH(key);
+		{{.partitions}}{{template "Register" .}}
 	  {{end}}
+
+	  {{define "Registry" -}}
+		CREATE TABLE IF NOT EXISTS {{.registry}}(
+		  bucket TEXT PRIMARY KEY,
+		  tbl TEXT NOT NULL
+		)
+	  {{- end}}
+
+	  {{define "Register"}}
+	    {{- if .registry}};
+		{{template "Registry" .}};
+		INSERT INTO {{.registry}}(bucket, tbl)
+		VALUES ({{.bucketLit}}, {{.physicalLit}})
+		ON CONFLICT (bucket) DO UPDATE SET tbl=EXCLUDED.tbl
+	    {{- end}}
+	  {{- end}}
 	`))
 	return queryGenerator{
-		tableChecker,
-		tmpl,
+		tableChecker:     tableChecker,
+		namespaceChecker: namespaceChecker,
+		tmpl:             tmpl,
+		mapper:           s2k.BucketMapperIdentity,
 	}
 }
 
-func (q *queryGenerator) generate(bucket string, name string) (query string, e error) {
-	e = q.tableChecker(bucket)
+// QuoteIdentifier quotes the identifier like pgx.Identifier.Sanitize
+func QuoteIdentifier(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// QuoteLiteral quotes the string literal.
+func QuoteLiteral(s string) string { return `'` + strings.ReplaceAll(s, `'`, `''`) + `'` }
+
+// SplitBucket splits the bucket into the namespace(empty: default schema) and the table name.
+func SplitBucket(bucket string) (namespace, name string) {
+	namespace, name, found := strings.Cut(bucket, ".")
+	if !found {
+		return "", bucket
+	}
+	return
+}
+
+// BucketTable gets the quoted (namespace qualified) table name of the bucket.
+func BucketTable(bucket string) string {
+	namespace, name := SplitBucket(bucket)
+	if "" == namespace {
+		return QuoteIdentifier(name)
+	}
+	return QuoteIdentifier(namespace) + "." + QuoteIdentifier(name)
+}
+
+// BucketPkc gets the quoted name of the primary key constraint of the bucket.
+func BucketPkc(bucket string) string {
+	_, name := SplitBucket(bucket)
+	return QuoteIdentifier(name + "_pkc")
+}
+
+// bucket2exp gets the name of the index on expires_at(TTL buckets).
+func bucket2exp(bucket string) string {
+	_, name := SplitBucket(bucket)
+	return QuoteIdentifier(name + "_exp")
+}
+
+// CheckIdentifiers rejects the table if its identifiers(with suffixes like "_pkc") exceed 63 bytes.
+func CheckIdentifiers(table string) error {
+	namespace, name := SplitBucket(table)
+	if 63 < len(namespace) || 63 < len(name)+len("_pkc") {
+		return fmt.Errorf("Too long identifier(%s): %w", table, s2k.ErrInvalidBucket)
+	}
+	return nil
+}
+
+// checkNamespace rejects the namespace longer than 63 bytes.
+func checkNamespace(namespace string) error {
+	if 63 < len(namespace) {
+		return fmt.Errorf("Too long namespace(%s): %w", namespace, s2k.ErrInvalidBucket)
+	}
+	return nil
+}
+
+func (q *queryGenerator) registryData(data map[string]string) map[string]string {
+	if "" != q.registry {
+		data["registry"] = BucketTable(q.registry)
+	}
+	return data
+}
+
+func (q *queryGenerator) generateWith(bucket string, name string, data map[string]string) (query string, e error) {
+	table, e := q.mapper(bucket)
+	if nil != e {
+		return "", e
+	}
+	e = q.tableChecker(table)
+	if nil != e {
+		return "", e
+	}
+	e = CheckIdentifiers(table)
 	if nil != e {
 		return "", e
 	}
 
+	data["tableName"] = BucketTable(table)
+	data["pkcName"] = BucketPkc(table)
+	data["expName"] = bucket2exp(table)
+	data["tableLit"] = QuoteLiteral(data["tableName"])
+	data["bucketLit"] = QuoteLiteral(bucket)
+	data["physicalLit"] = QuoteLiteral(table)
+	q.registryData(data)
+
 	var buf strings.Builder
-	e = q.tmpl.ExecuteTemplate(&buf, name, map[string]string{"tableName": bucket})
+	e = q.tmpl.ExecuteTemplate(&buf, name, data)
 	query = buf.String()
 	return
 }
 
+func (q *queryGenerator) generate(bucket string, name string) (query string, e error) {
+	return q.generateWith(bucket, name, make(map[string]string))
+}
+
+func placeholder(i int) string { return fmt.Sprintf("$%d", i) }
+
+// rangeData creates conditions with placeholders in the same order as s2k.Range.Args
+func rangeData(r s2k.Range) map[string]string {
+	conds, limit := r.Conditions("key", placeholder)
+	order := "ASC"
+	if r.Reverse {
+		order = "DESC"
+	}
+	return map[string]string{
+		"where": strings.Join(conds, " AND "),
+		"order": order,
+		"limit": limit,
+	}
+}
+
 func (q *queryGenerator) Get(bucket string) (query string, e error)  { return q.generate(bucket, "Get") }
+func (q *queryGenerator) GetMany(b string) (query string, e error)   { return q.generate(b, "GetMany") }
 func (q *queryGenerator) Del(bucket string) (query string, e error)  { return q.generate(bucket, "Del") }
 func (q *queryGenerator) Add(bucket string) (query string, e error)  { return q.generate(bucket, "Add") }
 func (q *queryGenerator) Set(bucket string) (query string, e error)  { return q.generate(bucket, "Set") }
 func (q *queryGenerator) Lst(bucket string) (query string, e error)  { return q.generate(bucket, "Lst") }
+func (q *queryGenerator) Scan(b string) (query string, e error)      { return q.generate(b, "Scan") }
 func (q *queryGenerator) DelBucket(b string) (query string, e error) { return q.generate(b, "BDel") }
 func (q *queryGenerator) AddBucket(b string) (query string, e error) { return q.generate(b, "BAdd") }
+
+func (q *queryGenerator) LstRange(bucket string, r s2k.Range) (query string, e error) {
+	return q.generateWith(bucket, "LstRange", rangeData(r))
+}
+
+func (q *queryGenerator) CasUpdate(b string) (string, error)        { return q.generate(b, "CasUpdate") }
+func (q *queryGenerator) CasInsert(b string) (string, error)        { return q.generate(b, "CasInsert") }
+func (q *queryGenerator) GetVersion(b string) (string, error)       { return q.generate(b, "VGet") }
+func (q *queryGenerator) CasVersionUpdate(b string) (string, error) { return q.generate(b, "VUpdate") }
+func (q *queryGenerator) CasVersionInsert(b string) (string, error) { return q.generate(b, "VInsert") }
+func (q *queryGenerator) AddVersionBucket(b string) (string, error) { return q.generate(b, "BAddV") }
+
+func (q *queryGenerator) TtlGet(b string) (string, error)       { return q.generate(b, "TGet") }
+func (q *queryGenerator) TtlSet(b string) (string, error)       { return q.generate(b, "TSet") }
+func (q *queryGenerator) Sweep(b string) (string, error)        { return q.generate(b, "Sweep") }
+func (q *queryGenerator) AddTtlBucket(b string) (string, error) { return q.generate(b, "BAddT") }
+
+func (q *queryGenerator) Incr(b string) (string, error) { return q.generate(b, "Incr") }
+
+func (q *queryGenerator) HasBucket(b string) (string, error)   { return q.generate(b, "BHas") }
+func (q *queryGenerator) BucketStats(b string) (string, error) { return q.generate(b, "BStats") }
+
+// generatePair generates the query for buckets src and dst(bucketLit/physicalLit: dst).
+func (q *queryGenerator) generatePair(src, dst string, name string) (query string, e error) {
+	srcTable, e := q.mapper(src)
+	if nil != e {
+		return "", e
+	}
+	dstTable, e := q.mapper(dst)
+	if nil != e {
+		return "", e
+	}
+	e = q.tableChecker(srcTable)
+	if nil != e {
+		return "", e
+	}
+	e = q.tableChecker(dstTable)
+	if nil != e {
+		return "", e
+	}
+	e = CheckIdentifiers(srcTable)
+	if nil != e {
+		return "", e
+	}
+	e = CheckIdentifiers(dstTable)
+	if nil != e {
+		return "", e
+	}
+
+	srcNamespace, srcName := SplitBucket(srcTable)
+	dstNamespace, dstName := SplitBucket(dstTable)
+	if "BRename" == name && srcNamespace != dstNamespace {
+		return "", fmt.Errorf("Unable to rename across namespaces(%s -> %s): %w", src, dst, s2k.ErrInvalidBucket)
+	}
+
+	// anonymous code block(<name>P)
+	// BRename: renames partitions(<src>_pN -> <dst>_pN)
+	// BCopy:   rejects partitioned src, recreates the index on expires_at
+	var body strings.Builder
+	e = q.tmpl.ExecuteTemplate(&body, name+"P", map[string]string{
+		"srcLit":     QuoteLiteral(BucketTable(srcTable)),
+		"srcNameLit": QuoteLiteral(srcName),
+		"dstNameLit": QuoteLiteral(dstName),
+		"srcExpLit":  QuoteLiteral(BucketTable(srcTable + "_exp")),
+		"dstLit":     QuoteLiteral(BucketTable(dstTable)),
+		"dstExpLit":  QuoteLiteral(bucket2exp(dstTable)),
+	})
+	if nil != e {
+		return "", e
+	}
+
+	var buf strings.Builder
+	e = q.tmpl.ExecuteTemplate(&buf, name, q.registryData(map[string]string{
+		"bodyLit":      QuoteLiteral(body.String()),
+		"src":          BucketTable(srcTable),
+		"dst":          BucketTable(dstTable),
+		"dstName":      QuoteIdentifier(dstName),
+		"srcPkc":       BucketPkc(srcTable),
+		"dstPkc":       BucketPkc(dstTable),
+		"srcExp":       BucketTable(srcTable + "_exp"),
+		"dstExp":       bucket2exp(dstTable),
+		"srcBucketLit": QuoteLiteral(src),
+		"bucketLit":    QuoteLiteral(dst),
+		"physicalLit":  QuoteLiteral(dstTable),
+	}))
+	return buf.String(), e
+}
+
+func (q *queryGenerator) RenameBucket(src, dst string) (string, error) {
+	return q.generatePair(src, dst, "BRename")
+}
+
+func (q *queryGenerator) CopyBucket(src, dst string) (string, error) {
+	return q.generatePair(src, dst, "BCopy")
+}
+
+func (q *queryGenerator) TruncateBucket(b string) (string, error) { return q.generate(b, "BTruncate") }
+
+func (q *queryGenerator) LstBucket() (query string, e error) {
+	var buf strings.Builder
+	e = q.tmpl.ExecuteTemplate(&buf, "BLst", q.registryData(make(map[string]string)))
+	return buf.String(), e
+}
+
+func (q *queryGenerator) generateNamespace(namespace string, name string) (query string, e error) {
+	e = q.namespaceChecker(namespace)
+	if nil != e {
+		return "", e
+	}
+	e = checkNamespace(namespace)
+	if nil != e {
+		return "", e
+	}
+
+	var buf strings.Builder
+	e = q.tmpl.ExecuteTemplate(&buf, name, map[string]string{"namespace": QuoteIdentifier(namespace)})
+	return buf.String(), e
+}
+
+func (q *queryGenerator) AddNamespace(namespace string) (string, error) {
+	return q.generateNamespace(namespace, "NAdd")
+}
+
+func (q *queryGenerator) DropNamespace(namespace string) (string, error) {
+	return q.generateNamespace(namespace, "NDel")
+}
+
+// partitionsQuery creates the statements which create partitions of the (mapped) table.
+func partitionsQuery(table string, partitions int) (string, error) {
+	if partitions < 1 {
+		return "", fmt.Errorf("Invalid number of partitions: %v", partitions)
+	}
+	namespace, name := SplitBucket(table)
+	suffix := fmt.Sprintf("_p%d", partitions-1)
+	if 63 < len(name)+len(suffix) {
+		return "", fmt.Errorf("Too long partition name(%s%s): %w", name, suffix, s2k.ErrInvalidBucket)
+	}
+	var stmts []string
+	for i := 0; i < partitions; i++ {
+		child := name + fmt.Sprintf("_p%d", i)
+		if "" != namespace {
+			child = namespace + "." + child
+		}
+		stmts = append(stmts, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES WITH (MODULUS %d, REMAINDER %d)",
+			BucketTable(child),
+			BucketTable(table),
+			partitions,
+			i,
+		))
+	}
+	return strings.Join(stmts, ";\n"), nil
+}
+
+func (q *queryGenerator) AddPartitionedBucket(bucket string, partitions int) (query string, e error) {
+	table, e := q.mapper(bucket)
+	if nil != e {
+		return "", e
+	}
+	stmts, e := partitionsQuery(table, partitions)
+	if nil != e {
+		return "", e
+	}
+	return q.generateWith(bucket, "BAddP", map[string]string{"partitions": stmts})
+}
@@ -1,8 +1,12 @@
 package pg
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
 )
 
 func TestNewQueryGeneratorMust(t *testing.T) {
@@ -31,6 +35,9 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 			if nil == e {
 				t.Errorf("Must reject invalid prefix")
 			}
+			if !errors.Is(e, s2k.ErrInvalidBucket) {
+				t.Errorf("Must be ErrInvalidBucket: %v", e)
+			}
 		})
 
 		t.Run("too long tablename", func(t *testing.T) {
@@ -50,7 +57,7 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				SELECT val FROM t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq
+				SELECT val FROM "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
 				WHERE key=$1
 				LIMIT 1
 			`
@@ -64,6 +71,29 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 		})
 	})
 
+	t.Run("GetMany", func(t *testing.T) {
+		t.Parallel()
+		t.Run("'short' tablename", func(t *testing.T) {
+			t.Parallel()
+			qgen := newQueryGeneratorMust()
+			query, e := qgen.GetMany("t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq")
+			if nil != e {
+				t.Errorf("Must accept 'short' tablename")
+			}
+			expected := `
+				SELECT key, val FROM "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
+				WHERE key = ANY($1)
+			`
+			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+			te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+			if tq != te {
+				t.Errorf("Unexpected value.\n")
+				t.Errorf("Expected: %s\n", te)
+				t.Errorf("Got: %s\n", tq)
+			}
+		})
+	})
+
 	t.Run("Lst", func(t *testing.T) {
 		t.Parallel()
 		t.Run("'short' tablename", func(t *testing.T) {
@@ -74,7 +104,30 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				SELECT key FROM t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq
+				SELECT key FROM "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
+				ORDER BY key
+			`
+			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+			te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+			if tq != te {
+				t.Errorf("Unexpected value.\n")
+				t.Errorf("Expected: %s\n", te)
+				t.Errorf("Got: %s\n", tq)
+			}
+		})
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		t.Parallel()
+		t.Run("'short' tablename", func(t *testing.T) {
+			t.Parallel()
+			qgen := newQueryGeneratorMust()
+			query, e := qgen.Scan("t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq")
+			if nil != e {
+				t.Errorf("Must accept 'short' tablename")
+			}
+			expected := `
+				SELECT key, val FROM "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
 				ORDER BY key
 			`
 			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
@@ -97,9 +150,9 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				INSERT INTO t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq AS alias_insert (key, val)
+				INSERT INTO "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq" AS alias_insert (key, val)
 				VALUES ($1, $2)
-				ON CONFLICT ON CONSTRAINT t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq_pkc
+				ON CONFLICT ON CONSTRAINT "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq_pkc"
 				DO UPDATE SET val=EXCLUDED.val
 				WHERE alias_insert.val != EXCLUDED.val
 			`
@@ -123,7 +176,7 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				DELETE FROM t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq
+				DELETE FROM "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
 				WHERE key=$1
 			`
 			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
@@ -146,7 +199,7 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				INSERT INTO t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq(key, val)
+				INSERT INTO "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"(key, val)
 				VALUES ($1, $2)
 			`
 			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
@@ -169,7 +222,7 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				DROP TABLE IF EXISTS t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq
+				DROP TABLE IF EXISTS "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
 			`
 			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
 			te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
@@ -191,10 +244,10 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 				t.Errorf("Must accept 'short' tablename")
 			}
 			expected := `
-				CREATE TABLE IF NOT EXISTS t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq(
+				CREATE TABLE IF NOT EXISTS "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"(
 				  key BYTEA,
 				  val BYTEA NOT NULL,
-				  CONSTRAINT t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq_pkc PRIMARY KEY(key)
+				  CONSTRAINT "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq_pkc" PRIMARY KEY(key)
 				)
 			`
 			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
@@ -206,4 +259,826 @@ func TestNewQueryGeneratorMust(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("LstRange", func(t *testing.T) {
+		t.Parallel()
+
+		pat := []struct {
+			r        s2k.Range
+			n        string
+			expected string
+		}{
+			{
+				r: s2k.Range{},
+				n: "unbounded",
+				expected: `
+					SELECT key FROM "t0"
+					ORDER BY key ASC
+				`,
+			},
+			{
+				r: s2k.Range{Start: []byte("a"), End: []byte("c"), Limit: 10},
+				n: "bounded",
+				expected: `
+					SELECT key FROM "t0"
+					WHERE $1 <= key AND key < $2
+					ORDER BY key ASC
+					LIMIT $3
+				`,
+			},
+			{
+				r: s2k.Range{Prefix: []byte("a"), Reverse: true},
+				n: "prefix reverse",
+				expected: `
+					SELECT key FROM "t0"
+					WHERE $1 <= key AND key < $2
+					ORDER BY key DESC
+				`,
+			},
+			{
+				r: s2k.Range{End: []byte("c"), Limit: 1},
+				n: "upper only",
+				expected: `
+					SELECT key FROM "t0"
+					WHERE key < $1
+					ORDER BY key ASC
+					LIMIT $2
+				`,
+			},
+		}
+
+		for _, p := range pat {
+			p := p
+			t.Run(p.n, func(t *testing.T) {
+				t.Parallel()
+				qgen := newQueryGeneratorMust()
+				query, e := qgen.LstRange("t0", p.r)
+				if nil != e {
+					t.Errorf("Must accept valid tablename: %v", e)
+				}
+				tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+				te := strings.ReplaceAll(strings.TrimSpace(p.expected), "	", "")
+				if tq != te {
+					t.Errorf("Unexpected value.\n")
+					t.Errorf("Expected: %s\n", te)
+					t.Errorf("Got: %s\n", tq)
+				}
+			})
+		}
+
+		t.Run("invalid bucket", func(t *testing.T) {
+			t.Parallel()
+			qgen := newQueryGeneratorMust()
+			_, e := qgen.LstRange("0zero", s2k.Range{})
+			if nil == e {
+				t.Errorf("Must reject invalid prefix")
+			}
+		})
+	})
+}
+
+func TestCasQueries(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	pat := []struct {
+		f        func(string) (string, error)
+		n        string
+		expected string
+	}{
+		{
+			f: qgen.CasUpdate,
+			n: "CasUpdate",
+			expected: `
+				UPDATE "t0"
+				SET val=$3
+				WHERE key=$1 AND val=$2
+				RETURNING key
+			`,
+		},
+		{
+			f: qgen.CasInsert,
+			n: "CasInsert",
+			expected: `
+				INSERT INTO "t0"(key, val)
+				VALUES ($1, $2)
+				ON CONFLICT ON CONSTRAINT "t0_pkc"
+				DO NOTHING
+				RETURNING key
+			`,
+		},
+		{
+			f: qgen.GetVersion,
+			n: "GetVersion",
+			expected: `
+				SELECT val, ver FROM "t0"
+				WHERE key=$1
+				LIMIT 1
+			`,
+		},
+		{
+			f: qgen.CasVersionUpdate,
+			n: "CasVersionUpdate",
+			expected: `
+				UPDATE "t0"
+				SET val=$3, ver=ver+1
+				WHERE key=$1 AND ver=$2
+				RETURNING ver
+			`,
+		},
+		{
+			f: qgen.CasVersionInsert,
+			n: "CasVersionInsert",
+			expected: `
+				INSERT INTO "t0"(key, val, ver)
+				VALUES ($1, $2, 1)
+				ON CONFLICT ON CONSTRAINT "t0_pkc"
+				DO NOTHING
+				RETURNING ver
+			`,
+		},
+		{
+			f: qgen.AddVersionBucket,
+			n: "AddVersionBucket",
+			expected: `
+				CREATE TABLE IF NOT EXISTS "t0"(
+				  key BYTEA,
+				  val BYTEA NOT NULL,
+				  ver BIGINT NOT NULL DEFAULT 1,
+				  CONSTRAINT "t0_pkc" PRIMARY KEY(key)
+				)
+			`,
+		},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+
+			_, e := p.f("0zero")
+			if nil == e {
+				t.Errorf("Must reject invalid prefix")
+			}
+
+			query, e := p.f("t0")
+			if nil != e {
+				t.Errorf("Must accept valid tablename: %v", e)
+			}
+			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+			te := strings.ReplaceAll(strings.TrimSpace(p.expected), "	", "")
+			if tq != te {
+				t.Errorf("Unexpected value.\n")
+				t.Errorf("Expected: %s\n", te)
+				t.Errorf("Got: %s\n", tq)
+			}
+		})
+	}
+}
+
+func TestTtlQueries(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	pat := []struct {
+		f        func(string) (string, error)
+		n        string
+		expected string
+	}{
+		{
+			f: qgen.TtlGet,
+			n: "TtlGet",
+			expected: `
+				SELECT val FROM "t0"
+				WHERE key=$1 AND (expires_at IS NULL OR CLOCK_TIMESTAMP() < expires_at)
+				LIMIT 1
+			`,
+		},
+		{
+			f: qgen.TtlSet,
+			n: "TtlSet",
+			expected: `
+				INSERT INTO "t0"(key, val, expires_at)
+				VALUES (
+				  $1,
+				  $2,
+				  CASE WHEN 0 < $3::BIGINT THEN CLOCK_TIMESTAMP() + $3::BIGINT * INTERVAL '1 microsecond' END
+				)
+				ON CONFLICT ON CONSTRAINT "t0_pkc"
+				DO UPDATE SET val=EXCLUDED.val, expires_at=EXCLUDED.expires_at
+			`,
+		},
+		{
+			f: qgen.Sweep,
+			n: "Sweep",
+			expected: `
+				WITH deleted AS (
+				  DELETE FROM "t0"
+				  WHERE ctid = ANY(ARRAY(
+				    SELECT ctid FROM "t0"
+				    WHERE expires_at <= CLOCK_TIMESTAMP()
+				    LIMIT $1
+				  ))
+				  RETURNING 1
+				)
+				SELECT COUNT(*) FROM deleted
+			`,
+		},
+		{
+			f: qgen.AddTtlBucket,
+			n: "AddTtlBucket",
+			expected: `
+				CREATE TABLE IF NOT EXISTS "t0"(
+				  key BYTEA,
+				  val BYTEA NOT NULL,
+				  expires_at TIMESTAMP WITH TIME ZONE,
+				  CONSTRAINT "t0_pkc" PRIMARY KEY(key)
+				);
+				CREATE INDEX IF NOT EXISTS "t0_exp" ON "t0"(expires_at)
+				WHERE expires_at IS NOT NULL
+			`,
+		},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+
+			_, e := p.f("0zero")
+			if nil == e {
+				t.Errorf("Must reject invalid prefix")
+			}
+
+			query, e := p.f("t0")
+			if nil != e {
+				t.Errorf("Must accept valid tablename: %v", e)
+			}
+			tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+			te := strings.ReplaceAll(strings.TrimSpace(p.expected), "	", "")
+			if tq != te {
+				t.Errorf("Unexpected value.\n")
+				t.Errorf("Expected: %s\n", te)
+				t.Errorf("Got: %s\n", tq)
+			}
+		})
+	}
+}
+
+func TestIncrQuery(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	_, e := qgen.Incr("0zero")
+	if nil == e {
+		t.Errorf("Must reject invalid prefix")
+	}
+
+	query, e := qgen.Incr("t0")
+	if nil != e {
+		t.Errorf("Must accept valid tablename: %v", e)
+	}
+	expected := `
+		INSERT INTO "t0" AS alias_insert (key, val)
+		VALUES ($1, INT8SEND($2::BIGINT))
+		ON CONFLICT ON CONSTRAINT "t0_pkc"
+		DO UPDATE SET val=INT8SEND(('x' || ENCODE(alias_insert.val, 'hex'))::BIT(64)::BIGINT + $2::BIGINT)
+		WHERE OCTET_LENGTH(alias_insert.val) = 8
+		RETURNING ('x' || ENCODE(val, 'hex'))::BIT(64)::BIGINT
+	`
+	tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+	te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+	if tq != te {
+		t.Errorf("Unexpected value.\n")
+		t.Errorf("Expected: %s\n", te)
+		t.Errorf("Got: %s\n", tq)
+	}
+}
+
+func TestBucketQueries(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	t.Run("LstBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.LstBucket()
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if !strings.Contains(query, "t.constraint_name = t.table_name || '_pkc'") {
+			t.Errorf("Must check the pkc convention: %s", query)
+		}
+		if !strings.Contains(query, "c.column_name IN ('key', 'val')") {
+			t.Errorf("Must check the key/val columns: %s", query)
+		}
+		if !strings.Contains(query, "t.table_schema || '.'") {
+			t.Errorf("Must list namespaced buckets: %s", query)
+		}
+	})
+
+	t.Run("HasBucket", func(t *testing.T) {
+		t.Parallel()
+		_, e := qgen.HasBucket("0zero")
+		if nil == e {
+			t.Errorf("Must reject invalid prefix")
+		}
+		query, e := qgen.HasBucket("t0")
+		if nil != e {
+			t.Errorf("Must accept valid tablename: %v", e)
+		}
+		if !strings.Contains(query, "table_name::TEXT = 't0'") {
+			t.Errorf("Must use bucket literal: %s", query)
+		}
+		if !strings.Contains(query, "c.column_name IN ('key', 'val')") {
+			t.Errorf("Must check the key/val columns: %s", query)
+		}
+	})
+}
+
+func TestBucketStatsQuery(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	_, e := qgen.BucketStats("0zero")
+	if nil == e {
+		t.Errorf("Must reject invalid prefix")
+	}
+
+	query, e := qgen.BucketStats("t0")
+	if nil != e {
+		t.Errorf("Must accept valid tablename: %v", e)
+	}
+	expected := `
+		SELECT
+		  COUNT(*),
+		  (
+		    SELECT (CASE WHEN MIN(c.reltuples) < 0 THEN -1 ELSE SUM(c.reltuples) END)::BIGINT
+		    FROM PG_PARTITION_TREE('"t0"'::REGCLASS) t JOIN pg_class c ON c.oid = t.relid
+		    WHERE t.isleaf
+		  ),
+		  (SELECT SUM(PG_TOTAL_RELATION_SIZE(relid))::BIGINT FROM PG_PARTITION_TREE('"t0"'::REGCLASS)),
+		  COALESCE(AVG(OCTET_LENGTH(val)), 0)::FLOAT8
+		FROM "t0"
+	`
+	tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+	te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+	if tq != te {
+		t.Errorf("Unexpected value.\n")
+		t.Errorf("Expected: %s\n", te)
+		t.Errorf("Got: %s\n", tq)
+	}
+}
+
+// skipPartitions removes the anonymous code block which renames partitions.
+func skipPartitions(query string) string {
+	_, rest, found := strings.Cut(query, "END';")
+	if !found {
+		return query
+	}
+	return rest
+}
+
+func TestBucketOpQueries(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	pat := []struct {
+		f        func(string, string) (string, error)
+		n        string
+		expected string
+	}{
+		{
+			f: qgen.RenameBucket,
+			n: "RenameBucket",
+			expected: `
+				ALTER TABLE "t0" RENAME TO "t1";
+				ALTER TABLE "t1" RENAME CONSTRAINT "t0_pkc" TO "t1_pkc";
+				ALTER INDEX IF EXISTS "t0_exp" RENAME TO "t1_exp"
+			`,
+		},
+		{
+			f: qgen.CopyBucket,
+			n: "CopyBucket",
+			expected: `
+				CREATE TABLE "t1" (LIKE "t0" INCLUDING ALL EXCLUDING INDEXES);
+				ALTER TABLE "t1" ADD CONSTRAINT "t1_pkc" PRIMARY KEY(key);
+				INSERT INTO "t1" SELECT * FROM "t0";
+				DO 'BEGIN
+				  IF EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = ''"t0"''::REGCLASS) THEN
+				    RAISE EXCEPTION USING ERRCODE = ''wrong_object_type'', MESSAGE = ''Unable to copy partitioned table: '' || ''"t0"'';
+				  END IF;
+				  IF TO_REGCLASS(''"t0_exp"'') IS NOT NULL THEN
+				    EXECUTE FORMAT(''CREATE INDEX %s ON %s(expires_at) WHERE expires_at IS NOT NULL'', ''"t1_exp"'', ''"t1"'');
+				  END IF;
+				END'
+			`,
+		},
+		{
+			f: func(b, _ string) (string, error) { return qgen.TruncateBucket(b) },
+			n: "TruncateBucket",
+			expected: `
+				TRUNCATE TABLE "t0"
+			`,
+		},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+
+			_, e := p.f("0zero", "t1")
+			if nil == e {
+				t.Errorf("Must reject invalid src")
+			}
+
+			query, e := p.f("t0", "t1")
+			if nil != e {
+				t.Errorf("Must accept valid tablename: %v", e)
+			}
+			tq := strings.ReplaceAll(strings.TrimSpace(skipPartitions(query)), "	", "")
+			te := strings.ReplaceAll(strings.TrimSpace(p.expected), "	", "")
+			if tq != te {
+				t.Errorf("Unexpected value.\n")
+				t.Errorf("Expected: %s\n", te)
+				t.Errorf("Got: %s\n", tq)
+			}
+		})
+	}
+
+	t.Run("invalid dst", func(t *testing.T) {
+		t.Parallel()
+		_, e := qgen.RenameBucket("t0", "1one")
+		if nil == e {
+			t.Errorf("Must reject invalid dst")
+		}
+	})
+
+	t.Run("partitions", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.RenameBucket("t0", "t1")
+		if nil != e {
+			t.Errorf("Must accept valid tablename: %v", e)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(query), "DO '") {
+			t.Errorf("Must rename partitions first: %s", query)
+		}
+		for _, expected := range []string{
+			`WHERE i.inhparent = ''"t0"''::REGCLASS`,
+			`SELECT c.oid::REGCLASS AS child, ''t1'' || SUBSTR(c.relname, LENGTH(''t0'') + 1) AS name`,
+			`ERRCODE = ''name_too_long''`,
+		} {
+			if !strings.Contains(query, expected) {
+				t.Errorf("Must contain %s: %s", expected, query)
+			}
+		}
+	})
+
+	t.Run("partitions quoted", func(t *testing.T) {
+		t.Parallel()
+		g := QueryGeneratorNew(func(_ string) error { return nil }, func(_ string) error { return nil })
+		query, e := g.(s2k.BucketOpQueryGenerator).RenameBucket(`t'0`, `t1`)
+		if nil != e {
+			t.Errorf("Must accept custom name: %v", e)
+		}
+		if !strings.Contains(query, `LENGTH(''t''''0'')`) {
+			t.Errorf("Must quote the name twice: %s", query)
+		}
+	})
+}
+
+func TestNamespace(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	t.Run("qualified bucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.AddBucket("ns0.t0")
+		if nil != e {
+			t.Errorf("Must accept qualified bucket: %v", e)
+		}
+		expected := `
+			CREATE TABLE IF NOT EXISTS "ns0"."t0"(
+			  key BYTEA,
+			  val BYTEA NOT NULL,
+			  CONSTRAINT "t0_pkc" PRIMARY KEY(key)
+			)
+		`
+		tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+		te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+		if tq != te {
+			t.Errorf("Unexpected value.\n")
+			t.Errorf("Expected: %s\n", te)
+			t.Errorf("Got: %s\n", tq)
+		}
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+		for _, b := range []string{"ns0.", ".t0", "ns0.t0.t1", "0ns.t0", `ns0."t0"`} {
+			_, e := qgen.Get(b)
+			if !errors.Is(e, s2k.ErrInvalidBucket) {
+				t.Errorf("Must reject invalid bucket(%s): %v", b, e)
+			}
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.RenameBucket("ns0.t0", "ns0.t1")
+		if nil != e {
+			t.Errorf("Must accept same namespace: %v", e)
+		}
+		expected := `
+			ALTER TABLE "ns0"."t0" RENAME TO "t1";
+			ALTER TABLE "ns0"."t1" RENAME CONSTRAINT "t0_pkc" TO "t1_pkc";
+			ALTER INDEX IF EXISTS "ns0"."t0_exp" RENAME TO "t1_exp"
+		`
+		tq := strings.ReplaceAll(strings.TrimSpace(skipPartitions(query)), "	", "")
+		te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+		if tq != te {
+			t.Errorf("Unexpected value.\n")
+			t.Errorf("Expected: %s\n", te)
+			t.Errorf("Got: %s\n", tq)
+		}
+
+		_, e = qgen.RenameBucket("ns0.t0", "ns1.t0")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject rename across namespaces: %v", e)
+		}
+	})
+
+	t.Run("AddNamespace", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.AddNamespace("ns0")
+		if nil != e {
+			t.Errorf("Must accept valid namespace: %v", e)
+		}
+		if `CREATE SCHEMA IF NOT EXISTS "ns0"` != strings.TrimSpace(query) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+		_, e = qgen.AddNamespace("ns0.t0")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject invalid namespace: %v", e)
+		}
+	})
+
+	t.Run("DropNamespace", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.DropNamespace("ns0")
+		if nil != e {
+			t.Errorf("Must accept valid namespace: %v", e)
+		}
+		if `DROP SCHEMA IF EXISTS "ns0"` != strings.TrimSpace(query) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+}
+
+func TestQueryGeneratorNew(t *testing.T) {
+	t.Parallel()
+
+	permissive := ValidatorRegexpNewMust(`^([^.]{1,59}\.)?[^.]{1,59}$`)
+	var qgen s2k.QueryGenerator = QueryGeneratorNew(permissive, permissive)
+	s2k.RegisterQueryGenerator("postgres-custom", qgen)
+
+	t.Run("uppercase, hyphen, unicode", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.Get("Team-A.バケット")
+		if nil != e {
+			t.Errorf("Must accept custom name: %v", e)
+		}
+		if !strings.Contains(query, `FROM "Team-A"."バケット"`) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("quoted", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.DelBucket(`x"; DROP TABLE y; --`)
+		if nil != e {
+			t.Errorf("Must accept custom name: %v", e)
+		}
+		if `DROP TABLE IF EXISTS "x""; DROP TABLE y; --"` != strings.TrimSpace(query) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("literal", func(t *testing.T) {
+		t.Parallel()
+		var g s2k.StatsQueryGenerator = qgen.(s2k.StatsQueryGenerator)
+		query, e := g.BucketStats(`it's`)
+		if nil != e {
+			t.Errorf("Must accept custom name: %v", e)
+		}
+		if !strings.Contains(query, `'"it''s"'::REGCLASS`) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+		_, e := qgen.Get("a.b.c")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		t.Parallel()
+		accept := func(_ string) error { return nil }
+		var g s2k.QueryGenerator = QueryGeneratorNew(accept, accept)
+		long := strings.Repeat("t", 60)
+		_, e := g.Get(long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long name: %v", e)
+		}
+		_, e = g.Get(strings.Repeat("t", 64) + ".t")
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long namespace: %v", e)
+		}
+		_, e = g.(s2k.BucketOpQueryGenerator).CopyBucket("t", long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long destination: %v", e)
+		}
+		_, e = g.(s2k.NamespaceQueryGenerator).AddNamespace(strings.Repeat("n", 64))
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long namespace: %v", e)
+		}
+		_, e = g.Get(strings.Repeat("t", 59))
+		if nil != e {
+			t.Errorf("Must accept 59 bytes: %v", e)
+		}
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		t.Parallel()
+		var got string
+		var del s2k.DelBucket = s2k.DelBucketFactory("postgres-custom")(func(_ context.Context, query string, _ ...any) error {
+			got = query
+			return nil
+		})
+		e := del(context.Background(), "Upper")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if `DROP TABLE IF EXISTS "Upper"` != strings.TrimSpace(got) {
+			t.Errorf("Unexpected query: %s", got)
+		}
+	})
+}
+
+func TestMappedQueryGeneratorNew(t *testing.T) {
+	t.Parallel()
+
+	checker := ValidatorRegexpNewMust(`^([a-z][0-9a-z_]{0,58}\.)?[a-z][0-9a-z_]{0,58}$`)
+	long := "bucket_" + strings.Repeat("x", 64)
+	hashed, _ := s2k.HashMapperNew("h_", 59)(long)
+	var qgen s2k.QueryGenerator = MappedQueryGeneratorNew(checker, checker, s2k.HashMapperNew("h_", 59), "s2k_buckets")
+
+	t.Run("mapped", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.Get(long)
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(query, `FROM "`+hashed+`"`) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("AddBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.AddBucket(long)
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		expected := `
+			CREATE TABLE IF NOT EXISTS "` + hashed + `"(
+			  key BYTEA,
+			  val BYTEA NOT NULL,
+			  CONSTRAINT "` + hashed + `_pkc" PRIMARY KEY(key)
+			);
+			CREATE TABLE IF NOT EXISTS "s2k_buckets"(
+			  bucket TEXT PRIMARY KEY,
+			  tbl TEXT NOT NULL
+			);
+			INSERT INTO "s2k_buckets"(bucket, tbl)
+			VALUES ('` + long + `', '` + hashed + `')
+			ON CONFLICT (bucket) DO UPDATE SET tbl=EXCLUDED.tbl
+		`
+		tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+		te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+		if tq != te {
+			t.Errorf("Unexpected value.\n")
+			t.Errorf("Expected: %s\n", te)
+			t.Errorf("Got: %s\n", tq)
+		}
+	})
+
+	t.Run("DelBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.DelBucket(long)
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(query, `DELETE FROM "s2k_buckets" WHERE bucket='`+long+`'`) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("RenameBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.(s2k.BucketOpQueryGenerator).RenameBucket(long, "b1")
+		if nil != e {
+			t.Errorf("Must accept long bucket: %v", e)
+		}
+		if !strings.Contains(query, `INSERT INTO "s2k_buckets"(bucket, tbl) VALUES ('b1', 'b1')`) {
+			t.Errorf("Unexpected query: %s", query)
+		}
+	})
+
+	t.Run("LstBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.(s2k.BucketQueryGenerator).LstBucket()
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if `SELECT bucket FROM "s2k_buckets"` != strings.Split(strings.TrimSpace(query), "\n")[0] {
+			t.Errorf("Must use registry: %s", query)
+		}
+	})
+
+	t.Run("HasBucket", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.(s2k.BucketQueryGenerator).HasBucket(long)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if !strings.Contains(query, `WHERE bucket='`+long+`'`) {
+			t.Errorf("Must use registry: %s", query)
+		}
+	})
+
+	t.Run("invalid mapped name", func(t *testing.T) {
+		t.Parallel()
+		var g s2k.QueryGenerator = MappedQueryGeneratorNew(checker, checker, s2k.HashMapperNew("0_", 59), "s2k_buckets")
+		_, e := g.Get(long)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must be ErrInvalidBucket: %v", e)
+		}
+	})
+}
+
+func TestAddPartitionedBucket(t *testing.T) {
+	t.Parallel()
+
+	qgen := newQueryGeneratorMust()
+
+	t.Run("partitions", func(t *testing.T) {
+		t.Parallel()
+		query, e := qgen.AddPartitionedBucket("ns0.t0", 2)
+		if nil != e {
+			t.Errorf("Must accept valid tablename: %v", e)
+		}
+		expected := `
+			CREATE TABLE IF NOT EXISTS "ns0"."t0"(
+			  key BYTEA,
+			  val BYTEA NOT NULL,
+			  CONSTRAINT "t0_pkc" PRIMARY KEY(key)
+			) PARTITION BY HASH(key);
+			CREATE TABLE IF NOT EXISTS "ns0"."t0_p0" PARTITION OF "ns0"."t0" FOR VALUES WITH (MODULUS 2, REMAINDER 0);
+			CREATE TABLE IF NOT EXISTS "ns0"."t0_p1" PARTITION OF "ns0"."t0" FOR VALUES WITH (MODULUS 2, REMAINDER 1)
+		`
+		tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+		te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+		if tq != te {
+			t.Errorf("Unexpected value.\n")
+			t.Errorf("Expected: %s\n", te)
+			t.Errorf("Got: %s\n", tq)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, e := qgen.AddPartitionedBucket("t0", 0)
+		if nil == e {
+			t.Errorf("Must reject invalid number of partitions")
+		}
+		_, e = qgen.AddPartitionedBucket("0zero", 2)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject invalid tablename: %v", e)
+		}
+		_, e = qgen.AddPartitionedBucket(strings.Repeat("t", 59), 128)
+		if !errors.Is(e, s2k.ErrInvalidBucket) {
+			t.Errorf("Must reject too long partition name: %v", e)
+		}
+	})
 }
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func init() {
+	qgen := newQueryGeneratorMust()
+	s2k.RegisterQueryGenerator("sqlite", &qgen)
+	s2k.RegisterQueryGenerator("sqlite3", &qgen)
+	s2k.RegisterErrorConverter(errorConvert)
+}
+
+var errorCode2sentinel = map[int64]error{
+	1555: s2k.ErrDuplicateKey, // SQLITE_CONSTRAINT_PRIMARYKEY
+	2067: s2k.ErrDuplicateKey, // SQLITE_CONSTRAINT_UNIQUE
+}
+
+// codeError is implemented by *sqlite.Error of modernc.org/sqlite(extended result code).
+type codeError interface{ Code() int }
+
+// errorCode gets the extended result code(ExtendedCode field of the errors defined in pkg).
+func errorCode(e error, pkg string) (code int64, found bool) {
+	var ce codeError
+	if errors.As(e, &ce) {
+		return int64(ce.Code()), true
+	}
+	return s2k.ErrorCode(e, pkg, "ExtendedCode")
+}
+
+// errorConverterNew creates a converter which also reads the code of the errors defined in pkg.
+func errorConverterNew(pkg string) s2k.ErrorConverter {
+	return func(e error) error {
+		code, found := errorCode(e, pkg)
+		if !found {
+			return nil
+		}
+		sentinel, known := errorCode2sentinel[code]
+		if known {
+			return sentinel
+		}
+		// missing tables are reported as a generic error(SQLITE_ERROR)
+		if strings.Contains(e.Error(), "no such table") {
+			return s2k.ErrBucketNotFound
+		}
+		return nil
+	}
+}
+
+// errorConvert converts errors by the extended result code.
+// mattn/go-sqlite3 exposes it only as ExtendedCode field(sqlite3.Error).
+var errorConvert s2k.ErrorConverter = errorConverterNew("github.com/mattn/go-sqlite3")
+
+type validator func(bucketName string) error
+
+type queryGenerator struct {
+	tableChecker validator
+	tmpl         *template.Template
+}
+
+func newValidatorRegexpMust(pattern string) validator {
+	re := regexp.MustCompile(pattern)
+	return func(bucketName string) error {
+		return s2k.Bool2error(
+			re.MatchString(bucketName),
+			func() error {
+				return fmt.Errorf("Invalid bucket name(%s): %w", bucketName, s2k.ErrInvalidBucket)
+			},
+		)
+	}
+}
+
+func newQueryGeneratorMust() queryGenerator {
+	// same rule as postgres to keep bucket names portable
+	// 0:     first char: [a-z]
+	// 1-58:  identifier
+	// 59-62: _pkc(reserved)
+	tableChecker := newValidatorRegexpMust(`^[a-z][0-9a-z_]{0,58}$`)
+	tmpl := template.Must(template.New("root").Parse(`
+	  {{define "Get"}}
+		SELECT val FROM {{.tableName}}
+		WHERE key=?
+		LIMIT 1
+	  {{end}}
+
+	  {{define "Lst"}}
+		SELECT key FROM {{.tableName}}
+		ORDER BY key
+	  {{end}}
+
+	  {{define "Del"}}
+		DELETE FROM {{.tableName}}
+		WHERE key=?
+	  {{end}}
+
+	  {{define "Add"}}
+		INSERT INTO {{.tableName}}(key, val)
+		VALUES (?, ?)
+	  {{end}}
+
+	  {{define "Set"}}
+		INSERT INTO {{.tableName}} AS alias_insert (key, val)
+		VALUES (?, ?)
+		ON CONFLICT(key)
+		DO UPDATE SET val=excluded.val
+		WHERE alias_insert.val != excluded.val
+	  {{end}}
+
+	  {{define "BDel"}}
+		DROP TABLE IF EXISTS {{.tableName}}
+	  {{end}}
+
+	  {{define "BAdd"}}
+		CREATE TABLE IF NOT EXISTS {{.tableName}}(
+		  key BLOB,
+		  val BLOB NOT NULL,
+		  CONSTRAINT {{.tableName}}_pkc PRIMARY KEY(key)
+		)
+	  {{end}}
+	`))
+	return queryGenerator{
+		tableChecker,
+		tmpl,
+	}
+}
+
+func (q *queryGenerator) generate(bucket string, name string) (query string, e error) {
+	e = q.tableChecker(bucket)
+	if nil != e {
+		return "", e
+	}
+
+	var buf strings.Builder
+	e = q.tmpl.ExecuteTemplate(&buf, name, map[string]string{"tableName": bucket})
+	query = buf.String()
+	return
+}
+
+func (q *queryGenerator) Get(bucket string) (query string, e error)  { return q.generate(bucket, "Get") }
+func (q *queryGenerator) Del(bucket string) (query string, e error)  { return q.generate(bucket, "Del") }
+func (q *queryGenerator) Add(bucket string) (query string, e error)  { return q.generate(bucket, "Add") }
+func (q *queryGenerator) Set(bucket string) (query string, e error)  { return q.generate(bucket, "Set") }
+func (q *queryGenerator) Lst(bucket string) (query string, e error)  { return q.generate(bucket, "Lst") }
+func (q *queryGenerator) DelBucket(b string) (query string, e error) { return q.generate(b, "BDel") }
+func (q *queryGenerator) AddBucket(b string) (query string, e error) { return q.generate(b, "BAdd") }
@@ -0,0 +1,240 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+)
+
+func checkQuery(t *testing.T, query, expected string) {
+	tq := strings.ReplaceAll(strings.TrimSpace(query), "	", "")
+	te := strings.ReplaceAll(strings.TrimSpace(expected), "	", "")
+	if tq != te {
+		t.Errorf("Unexpected value.\n")
+		t.Errorf("Expected: %s\n", te)
+		t.Errorf("Got: %s\n", tq)
+	}
+}
+
+func TestNewQueryGeneratorMust(t *testing.T) {
+	t.Parallel()
+
+	const tname = "t123456789abcdefghijklmnopqrstuv0123456789abcdefghijklmnopq"
+
+	t.Run("no panic", func(t *testing.T) {
+		t.Parallel()
+		newQueryGeneratorMust()
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		t.Parallel()
+
+		pat := []struct {
+			b string
+			n string
+		}{
+			{b: "", n: "empty bucket"},
+			{b: "0zero", n: "first char is number"},
+			{b: "Upper", n: "upper case"},
+			{b: "t; DROP TABLE t", n: "injection"},
+			{b: tname + "rstuv", n: "too long tablename"},
+		}
+
+		for _, p := range pat {
+			p := p
+			t.Run(p.n, func(t *testing.T) {
+				t.Parallel()
+				qgen := newQueryGeneratorMust()
+				_, e := qgen.Get(p.b)
+				if nil == e {
+					t.Errorf("Must reject invalid bucket: %s", p.b)
+				}
+			})
+		}
+	})
+
+	pat := []struct {
+		f        func(q *queryGenerator) func(string) (string, error)
+		n        string
+		expected string
+	}{
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.Get },
+			n: "Get",
+			expected: `
+				SELECT val FROM ` + tname + `
+				WHERE key=?
+				LIMIT 1
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.Lst },
+			n: "Lst",
+			expected: `
+				SELECT key FROM ` + tname + `
+				ORDER BY key
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.Del },
+			n: "Del",
+			expected: `
+				DELETE FROM ` + tname + `
+				WHERE key=?
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.Add },
+			n: "Add",
+			expected: `
+				INSERT INTO ` + tname + `(key, val)
+				VALUES (?, ?)
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.Set },
+			n: "Set",
+			expected: `
+				INSERT INTO ` + tname + ` AS alias_insert (key, val)
+				VALUES (?, ?)
+				ON CONFLICT(key)
+				DO UPDATE SET val=excluded.val
+				WHERE alias_insert.val != excluded.val
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.DelBucket },
+			n: "BDel",
+			expected: `
+				DROP TABLE IF EXISTS ` + tname + `
+			`,
+		},
+		{
+			f: func(q *queryGenerator) func(string) (string, error) { return q.AddBucket },
+			n: "BAdd",
+			expected: `
+				CREATE TABLE IF NOT EXISTS ` + tname + `(
+				  key BLOB,
+				  val BLOB NOT NULL,
+				  CONSTRAINT ` + tname + `_pkc PRIMARY KEY(key)
+				)
+			`,
+		},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+			qgen := newQueryGeneratorMust()
+			query, e := p.f(&qgen)(tname)
+			if nil != e {
+				t.Errorf("Must accept 'short' tablename: %v", e)
+			}
+			checkQuery(t, query, p.expected)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	t.Parallel()
+
+	for _, driverName := range []string{"sqlite", "sqlite3"} {
+		driverName := driverName
+		t.Run(driverName, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+			var dummyExec s2k.Exec = func(_ context.Context, query string, _ ...any) error {
+				got = query
+				return nil
+			}
+
+			var del s2k.Del = s2k.DelFactory(driverName)(dummyExec)
+			e := del(context.Background(), "bucket0", []byte("k"))
+			if nil != e {
+				t.Errorf("Unexpected error: %v", e)
+			}
+			checkQuery(t, got, `
+				DELETE FROM bucket0
+				WHERE key=?
+			`)
+		})
+	}
+}
+
+// Error has the same shape as sqlite3.Error of mattn/go-sqlite3.
+type Error struct {
+	Code         int
+	ExtendedCode int
+	err          string
+}
+
+func (e Error) Error() string { return e.err }
+
+// moderncError has the same shape as *sqlite.Error of modernc.org/sqlite.
+type moderncError struct {
+	msg  string
+	code int
+}
+
+func (e *moderncError) Error() string { return e.msg }
+func (e *moderncError) Code() int     { return e.code }
+
+func TestErrorConvert(t *testing.T) {
+	t.Parallel()
+
+	pat := []struct {
+		name     string
+		native   error
+		sentinel error
+	}{
+		{name: "mattn primary key", native: Error{19, 1555, "UNIQUE constraint failed: t.key"}, sentinel: s2k.ErrDuplicateKey},
+		{name: "mattn no such table", native: Error{1, 1, "no such table: t"}, sentinel: s2k.ErrBucketNotFound},
+		{name: "modernc unique", native: &moderncError{"UNIQUE constraint failed: t.key", 2067}, sentinel: s2k.ErrDuplicateKey},
+		{name: "modernc no such table", native: &moderncError{"SQL logic error: no such table: t (1)", 1}, sentinel: s2k.ErrBucketNotFound},
+	}
+
+	// the fake of sqlite3.Error is defined in this package
+	convert := errorConverterNew(reflect.TypeOf(Error{}).PkgPath())
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.name, func(t *testing.T) {
+			t.Parallel()
+			sentinel := convert(fmt.Errorf("wrapped: %w", p.native))
+			if p.sentinel != sentinel {
+				t.Errorf("Must be converted: %v", sentinel)
+			}
+		})
+	}
+
+	t.Run("unknown code", func(t *testing.T) {
+		t.Parallel()
+		native := Error{1, 1, "syntax error"}
+		if nil != convert(native) {
+			t.Errorf("Must not be converted")
+		}
+	})
+
+	t.Run("modernc", func(t *testing.T) {
+		t.Parallel()
+		native := &moderncError{"UNIQUE constraint failed: t.key", 2067}
+		if !errors.Is(s2k.ErrorFromSqlState(native), s2k.ErrDuplicateKey) {
+			t.Errorf("Must be converted by Code()")
+		}
+	})
+
+	t.Run("other package", func(t *testing.T) {
+		t.Parallel()
+		native := Error{19, 1555, "UNIQUE constraint failed: t.key"}
+		if error(native) != s2k.ErrorFromSqlState(native) {
+			t.Errorf("Must ignore errors not defined in go-sqlite3")
+		}
+	})
+}
@@ -3,47 +3,137 @@ package stdsql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	s2k "github.com/takanoriyanagitani/go-sql2keyval"
 )
 
+// ErrorConvert converts native errors(sql.ErrNoRows, SQLSTATE) into the sentinel errors of s2k.
+func ErrorConvert(e error) error {
+	if nil == e {
+		return nil
+	}
+	if errors.Is(e, sql.ErrNoRows) {
+		return s2k.ErrorConvertNew(s2k.ErrNotFound, e)
+	}
+	return s2k.ErrorFromSqlState(e)
+}
+
+type record struct{ row *sql.Row }
+
+func (r record) Scan(dest ...any) error { return ErrorConvert(r.row.Scan(dest...)) }
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 func DbOpenNew(driverName string) func(conn string) (*sql.DB, error) {
 	return func(conn string) (*sql.DB, error) {
 		return sql.Open(driverName, conn)
 	}
 }
 
-func QueryNew(d *sql.DB) s2k.Query {
+func queryNew(q querier) s2k.Query {
 	return func(ctx context.Context, query string, args ...any) s2k.Record {
-		return d.QueryRowContext(ctx, query, args...)
+		return record{q.QueryRowContext(ctx, query, args...)}
 	}
 }
 
-func QueryCbNew(d *sql.DB) s2k.QueryCb {
+func queryCbNew(q querier) s2k.QueryCb {
 	return func(ctx context.Context, cb s2k.RecordConsumer, query string, args ...any) error {
-		rows, e := d.QueryContext(ctx, query, args...)
+		rows, e := q.QueryContext(ctx, query, args...)
 		if nil != e {
-			return fmt.Errorf("Unable to get rows: %v", e)
+			return fmt.Errorf("Unable to get rows: %w", ErrorConvert(e))
 		}
 		defer rows.Close()
 
 		for rows.Next() {
 			e = cb(rows)
 			if nil != e {
-				return fmt.Errorf("Unable to process row: %v", e)
+				return fmt.Errorf("Unable to process row: %w", e)
 			}
 		}
-		return rows.Err()
+		return ErrorConvert(rows.Err())
 	}
 }
 
-func ExecNew(d *sql.DB) s2k.Exec {
+func execNew(q querier) s2k.Exec {
 	return func(ctx context.Context, query string, args ...any) error {
-		_, e := d.ExecContext(ctx, query, args...)
+		_, e := q.ExecContext(ctx, query, args...)
 		if nil == e {
 			return nil
 		}
-		return fmt.Errorf("Unable to execute query(arg len: %v, q: %s): %v", len(args), query, e)
+		return fmt.Errorf("Unable to execute query(arg len: %v, q: %s): %w", len(args), query, ErrorConvert(e))
+	}
+}
+
+func storeNew(driverName string, q querier) s2k.Store {
+	return s2k.StoreNew(driverName, queryNew(q), queryCbNew(q), execNew(q))
+}
+
+func QueryNew(d *sql.DB) s2k.Query     { return queryNew(d) }
+func QueryCbNew(d *sql.DB) s2k.QueryCb { return queryCbNew(d) }
+func ExecNew(d *sql.DB) s2k.Exec       { return execNew(d) }
+
+func TxQueryNew(t *sql.Tx) s2k.Query     { return queryNew(t) }
+func TxQueryCbNew(t *sql.Tx) s2k.QueryCb { return queryCbNew(t) }
+func TxExecNew(t *sql.Tx) s2k.Exec       { return execNew(t) }
+
+func StoreNew(driverName string) func(d *sql.DB) s2k.Store {
+	return func(d *sql.DB) s2k.Store {
+		return storeNew(driverName, d)
+	}
+}
+
+func TxStoreNew(driverName string) func(t *sql.Tx) s2k.Store {
+	return func(t *sql.Tx) s2k.Store {
+		return storeNew(driverName, t)
+	}
+}
+
+func LstBucketNew(driverName string) func(d *sql.DB) s2k.LstBucket {
+	return func(d *sql.DB) s2k.LstBucket {
+		return s2k.LstBucketFactory(driverName)(queryCbNew(d))
+	}
+}
+
+func HasBucketNew(driverName string) func(d *sql.DB) s2k.HasBucket {
+	return func(d *sql.DB) s2k.HasBucket {
+		return s2k.HasBucketFactory(driverName)(queryNew(d))
+	}
+}
+
+func withTx(ctx context.Context, d *sql.DB, newStore func(*sql.Tx) s2k.Store, f func(tx s2k.Store) error) (e error) {
+	tx, e := d.BeginTx(ctx, nil)
+	if nil != e {
+		return fmt.Errorf("Unable to begin transaction: %w", e)
+	}
+
+	defer func() {
+		r := recover()
+		if nil != r {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	e = f(newStore(tx))
+	if nil != e {
+		_ = tx.Rollback()
+		return e
+	}
+	return tx.Commit()
+}
+
+func WithTxNew(driverName string) func(d *sql.DB) s2k.WithTx {
+	return func(d *sql.DB) s2k.WithTx {
+		var newStore func(*sql.Tx) s2k.Store = TxStoreNew(driverName)
+		return func(ctx context.Context, f func(tx s2k.Store) error) error {
+			return withTx(ctx, d, newStore, f)
+		}
 	}
 }
@@ -1,8 +1,17 @@
 package stdsql
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
 	"testing"
+
+	s2k "github.com/takanoriyanagitani/go-sql2keyval"
+	pg "github.com/takanoriyanagitani/go-sql2keyval/pkg/sqldb/postgres"
 )
 
 func TestDbOpenNew(t *testing.T) {
@@ -28,3 +37,224 @@ func TestExecNew(t *testing.T) {
 	t.Parallel()
 	ExecNew(nil)
 }
+
+type dummySqlStateError struct{ state string }
+
+func (d dummySqlStateError) Error() string    { return "dummy: " + d.state }
+func (d dummySqlStateError) SQLState() string { return d.state }
+
+func TestErrorConvert(t *testing.T) {
+	t.Parallel()
+
+	pat := []struct {
+		native   error
+		n        string
+		sentinel error
+	}{
+		{native: sql.ErrNoRows, n: "no rows", sentinel: s2k.ErrNotFound},
+		{native: dummySqlStateError{"42P01"}, n: "undefined table", sentinel: s2k.ErrBucketNotFound},
+		{native: dummySqlStateError{"23505"}, n: "unique violation", sentinel: s2k.ErrDuplicateKey},
+		{native: fmt.Errorf("wrapped: %w", dummySqlStateError{"23505"}), n: "wrapped", sentinel: s2k.ErrDuplicateKey},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+			e := ErrorConvert(p.native)
+			if !errors.Is(e, p.sentinel) {
+				t.Errorf("Must be converted: %v", e)
+			}
+			if !errors.Is(e, p.native) {
+				t.Errorf("Must keep native error: %v", e)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		if nil != ErrorConvert(nil) {
+			t.Errorf("Must be nil")
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		native := dummySqlStateError{"42000"}
+		e := ErrorConvert(native)
+		if e != error(native) {
+			t.Errorf("Must not be converted: %v", e)
+		}
+	})
+}
+
+func TestStoreNew(t *testing.T) {
+	t.Parallel()
+	var s s2k.Store = StoreNew("does-not-exist")(nil)
+	e := s.AddBucket(context.Background(), "b")
+	if nil == e {
+		t.Errorf("Must fail")
+	}
+}
+
+func TestBucketNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("LstBucketNew", func(t *testing.T) {
+		t.Parallel()
+		var l s2k.LstBucket = LstBucketNew("does-not-exist")(nil)
+		e := l(context.Background(), func(_ string) error { return nil })
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("HasBucketNew", func(t *testing.T) {
+		t.Parallel()
+		var h s2k.HasBucket = HasBucketNew("does-not-exist")(nil)
+		_, e := h(context.Background(), "b")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
+
+type txCounter struct {
+	lock     sync.Mutex
+	commit   int
+	rollback int
+}
+
+type dummyDriver struct{ cnt *txCounter }
+type dummyConn struct{ cnt *txCounter }
+type dummyTx struct{ cnt *txCounter }
+
+func (d dummyDriver) Open(_ string) (driver.Conn, error) { return dummyConn(d), nil }
+
+func (c dummyConn) Prepare(_ string) (driver.Stmt, error) { return nil, fmt.Errorf("unsupported") }
+func (c dummyConn) Close() error                          { return nil }
+func (c dummyConn) Begin() (driver.Tx, error)             { return dummyTx(c), nil }
+
+func (t dummyTx) Commit() error {
+	t.cnt.lock.Lock()
+	defer t.cnt.lock.Unlock()
+	t.cnt.commit += 1
+	return nil
+}
+
+func (t dummyTx) Rollback() error {
+	t.cnt.lock.Lock()
+	defer t.cnt.lock.Unlock()
+	t.cnt.rollback += 1
+	return nil
+}
+
+func TestWithTxNew(t *testing.T) {
+	t.Parallel()
+
+	var cnt txCounter
+	sql.Register("stdsql-dummy-tx", dummyDriver{&cnt})
+	d, e := DbOpenNew("stdsql-dummy-tx")("")
+	if nil != e {
+		t.Fatalf("Unable to open: %v", e)
+	}
+	defer d.Close()
+
+	var withTx s2k.WithTx = WithTxNew("does-not-exist")(d)
+	ctx := context.Background()
+
+	// non parallel
+	t.Run("commit", func(t *testing.T) {
+		e := withTx(ctx, func(_ s2k.Store) error { return nil })
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 1 != cnt.commit {
+			t.Errorf("Must be committed")
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		e := withTx(ctx, func(tx s2k.Store) error {
+			return tx.Set(ctx, "b", nil, nil)
+		})
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+		if 1 != cnt.rollback {
+			t.Errorf("Must be rolled back")
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		defer func() {
+			if nil == recover() {
+				t.Errorf("Must panic")
+			}
+			if 2 != cnt.rollback {
+				t.Errorf("Must be rolled back")
+			}
+		}()
+		_ = withTx(ctx, func(_ s2k.Store) error { panic("must rollback") })
+	})
+}
+
+// argsDriver records query arguments converted by database/sql(no driver.NamedValueChecker like lib/pq).
+type argsDriver struct{ args *[]driver.NamedValue }
+type argsConn struct{ args *[]driver.NamedValue }
+type emptyRows struct{}
+
+func (d argsDriver) Open(_ string) (driver.Conn, error) { return argsConn(d), nil }
+
+func (c argsConn) Prepare(_ string) (driver.Stmt, error) { return nil, fmt.Errorf("unsupported") }
+func (c argsConn) Close() error                          { return nil }
+func (c argsConn) Begin() (driver.Tx, error)             { return nil, fmt.Errorf("unsupported") }
+func (c argsConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.args = args
+	return emptyRows{}, nil
+}
+
+func (r emptyRows) Columns() []string           { return []string{"key", "val"} }
+func (r emptyRows) Close() error                { return nil }
+func (r emptyRows) Next(_ []driver.Value) error { return io.EOF }
+
+func TestGetManyArgs(t *testing.T) {
+	t.Parallel()
+
+	var args []driver.NamedValue
+	sql.Register("stdsql-dummy-getmany", argsDriver{&args})
+	s2k.RegisterQueryGenerator("stdsql-dummy-getmany", pg.QueryGeneratorNew(
+		pg.ValidatorRegexpNewMust(`^[a-z]+$`),
+		pg.ValidatorRegexpNewMust(`^[a-z]+$`),
+	))
+	d, e := DbOpenNew("stdsql-dummy-getmany")("")
+	if nil != e {
+		t.Fatalf("Unable to open: %v", e)
+	}
+	defer d.Close()
+
+	ctx := context.Background()
+	keys := [][]byte{[]byte("k")}
+
+	// non parallel
+	t.Run("raw keys rejected", func(t *testing.T) {
+		_, e := d.QueryContext(ctx, "SELECT 1", keys)
+		if nil == e {
+			t.Errorf("Must be rejected by the default converter")
+		}
+	})
+
+	t.Run("GetMany", func(t *testing.T) {
+		var gm s2k.GetMany = s2k.GetManyFactory("stdsql-dummy-getmany")(QueryCbNew(d))
+		_, missing, e := gm(ctx, "b", keys)
+		if nil != e {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+		if 1 != len(missing) {
+			t.Errorf("Unexpected missing keys: %v", missing)
+		}
+		if 1 != len(args) || `{"\\x6b"}` != args[0].Value {
+			t.Errorf("Unexpected args: %v", args)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package sql2keyval
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Range selects keys in [Start, End) which have the Prefix.
+// Empty Start/End/Prefix means unbounded, 0 Limit means no limit.
+type Range struct {
+	Start   []byte
+	End     []byte
+	Prefix  []byte
+	Limit   int64
+	Reverse bool
+}
+
+// prefixEnd gets the smallest key which is greater than all keys with the prefix.
+// Returns nil if no such key exists(empty prefix or 0xff only).
+func prefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; 0 <= i; i-- {
+		if 0xff != prefix[i] {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i] += 1
+			return end
+		}
+	}
+	return nil
+}
+
+// Lower gets the inclusive lower bound(nil: unbounded).
+func (r Range) Lower() []byte {
+	if 0 < bytes.Compare(r.Prefix, r.Start) {
+		return r.Prefix
+	}
+	if 0 < len(r.Start) {
+		return r.Start
+	}
+	return nil
+}
+
+// Upper gets the exclusive upper bound(nil: unbounded).
+func (r Range) Upper() []byte {
+	pe := prefixEnd(r.Prefix)
+	switch {
+	case 0 == len(pe):
+		return nilIfEmpty(r.End)
+	case 0 == len(r.End):
+		return pe
+	case bytes.Compare(pe, r.End) < 0:
+		return pe
+	default:
+		return r.End
+	}
+}
+
+func nilIfEmpty(b []byte) []byte {
+	if 0 < len(b) {
+		return b
+	}
+	return nil
+}
+
+// Args gets query arguments in order: lower, upper, limit(bounded ones only).
+func (r Range) Args() (args []any) {
+	if lower := r.Lower(); nil != lower {
+		args = append(args, lower)
+	}
+	if upper := r.Upper(); nil != upper {
+		args = append(args, upper)
+	}
+	if 0 < r.Limit {
+		args = append(args, r.Limit)
+	}
+	return
+}
+
+// Conditions gets the conditions on the column and the limit with placeholders in the same order as Args.
+// placeholder gets the placeholder of the i-th argument(1-origin), e.g. "$1".
+func (r Range) Conditions(column string, placeholder func(i int) string) (conds []string, limit string) {
+	i := 1
+	if nil != r.Lower() {
+		conds = append(conds, placeholder(i)+" <= "+column)
+		i += 1
+	}
+	if nil != r.Upper() {
+		conds = append(conds, column+" < "+placeholder(i))
+		i += 1
+	}
+	if 0 < r.Limit {
+		limit = placeholder(i)
+	}
+	return
+}
+
+type LstRange func(ctx context.Context, bucket string, r Range, cb func(key []byte) error) error
+
+type RangeQueryGenerator interface {
+	LstRange(bucket string, r Range) (query string, e error)
+}
+
+func listRangeNew(g RangeQueryGenerator, q QueryCb) LstRange {
+	return func(ctx context.Context, bucket string, r Range, cb func(key []byte) error) error {
+		query, e := g.LstRange(bucket, r)
+		if nil != e {
+			return fmt.Errorf("Unable to get query for listing: %w", e)
+		}
+		return q(
+			ctx,
+			func(r Record) error {
+				v, e := record2val(r)
+				if nil != e {
+					return fmt.Errorf("Unable to get value as byte array: %w", e)
+				}
+				return cb(v)
+			},
+			query,
+			r.Args()...,
+		)
+	}
+}
+
+var lstRangeFactory func(RangeQueryGenerator) func(QueryCb) LstRange = curry(listRangeNew)
+
+var LstRangeFactory func(driverName string) func(QueryCb) LstRange = compose(
+	getQueryGeneratorExtOrEmpty[RangeQueryGenerator],
+	lstRangeFactory,
+)
@@ -0,0 +1,104 @@
+package sql2keyval
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	t.Parallel()
+
+	pat := []struct {
+		r     Range
+		n     string
+		lower []byte
+		upper []byte
+		args  int
+	}{
+		{r: Range{}, n: "unbounded", lower: nil, upper: nil, args: 0},
+		{r: Range{Limit: 3}, n: "limit only", lower: nil, upper: nil, args: 1},
+		{r: Range{Start: []byte("a"), End: []byte("c")}, n: "start/end", lower: []byte("a"), upper: []byte("c"), args: 2},
+		{r: Range{Prefix: []byte("ab")}, n: "prefix", lower: []byte("ab"), upper: []byte("ac"), args: 2},
+		{r: Range{Prefix: []byte{0x01, 0xff}}, n: "prefix carry", lower: []byte{0x01, 0xff}, upper: []byte{0x02}, args: 2},
+		{r: Range{Prefix: []byte{0xff, 0xff}}, n: "prefix no upper", lower: []byte{0xff, 0xff}, upper: nil, args: 1},
+		{r: Range{Start: []byte("b"), Prefix: []byte("a")}, n: "start after prefix", lower: []byte("b"), upper: []byte("b"), args: 2},
+		{r: Range{Start: []byte("a"), End: []byte("ab"), Prefix: []byte("a"), Limit: 1}, n: "end before prefix end", lower: []byte("a"), upper: []byte("ab"), args: 3},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+			if 0 != bytes.Compare(p.r.Lower(), p.lower) {
+				t.Errorf("Unexpected lower bound: %v", p.r.Lower())
+			}
+			if 0 != bytes.Compare(p.r.Upper(), p.upper) {
+				t.Errorf("Unexpected upper bound: %v", p.r.Upper())
+			}
+			if p.args != len(p.r.Args()) {
+				t.Errorf("Unexpected number of args: %v", len(p.r.Args()))
+			}
+		})
+	}
+}
+
+func TestRangeConditions(t *testing.T) {
+	t.Parallel()
+
+	placeholder := func(i int) string { return fmt.Sprintf("$%d", i) }
+
+	pat := []struct {
+		r     Range
+		n     string
+		conds string
+		limit string
+	}{
+		{r: Range{}, n: "unbounded", conds: "", limit: ""},
+		{r: Range{Limit: 3}, n: "limit only", conds: "", limit: "$1"},
+		{r: Range{End: []byte("c"), Limit: 3}, n: "end/limit", conds: "key < $1", limit: "$2"},
+		{r: Range{Prefix: []byte("ab"), Limit: 1}, n: "prefix/limit", conds: "$1 <= key,key < $2", limit: "$3"},
+	}
+
+	for _, p := range pat {
+		p := p
+		t.Run(p.n, func(t *testing.T) {
+			t.Parallel()
+			conds, limit := p.r.Conditions("key", placeholder)
+			if p.conds != strings.Join(conds, ",") {
+				t.Errorf("Unexpected conditions: %v", conds)
+			}
+			if p.limit != limit {
+				t.Errorf("Unexpected limit: %s", limit)
+			}
+		})
+	}
+}
+
+func TestLstRangeFactory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var dummyFactory func(QueryCb) LstRange = LstRangeFactory("does-not-exist")
+		var lister LstRange = dummyFactory(nil)
+		e := lister(context.Background(), "", Range{}, nil)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+		RegisterQueryGenerator("unsupported-range", unsupportedQueryGenerator{})
+		var lister LstRange = LstRangeFactory("unsupported-range")(nil)
+		e := lister(context.Background(), "", Range{}, nil)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+}
+
+type unsupportedQueryGenerator struct{ QueryGenerator }
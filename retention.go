@@ -0,0 +1,36 @@
+package sql2keyval
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Retention describes which log entries may be deleted; zero values disable each rule.
+// An entry is deleted if any enabled rule matches.
+type Retention struct {
+	MaxAge         time.Duration // entries older than MaxAge
+	MaxRows        int64         // entries except the latest MaxRows entries
+	BelowCommitted bool          // entries committed by all consumer groups
+}
+
+// Validate rejects negative values and retentions without rules.
+func (r Retention) Validate() error {
+	if r.MaxAge < 0 || r.MaxRows < 0 {
+		return fmt.Errorf("Invalid retention: %+v", r)
+	}
+	if 0 == r.MaxAge && 0 == r.MaxRows && !r.BelowCommitted {
+		return fmt.Errorf("No retention rule: %+v", r)
+	}
+	return nil
+}
+
+// Compact deletes at most limit log entries which matches the retention.
+type Compact func(ctx context.Context, bucket string, r Retention, limit int64) (deleted int64, e error)
+
+// CompactSweepNew creates Sweep which compacts logs so that SweepAll/SweeperStart can be used as a compaction job.
+func CompactSweepNew(c Compact, r Retention) Sweep {
+	return func(ctx context.Context, bucket string, limit int64) (deleted int64, e error) {
+		return c(ctx, bucket, r, limit)
+	}
+}
@@ -0,0 +1,62 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetention(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Validate", func(t *testing.T) {
+		t.Parallel()
+
+		var invalid = []Retention{
+			{},
+			{MaxAge: -time.Second},
+			{MaxRows: -1},
+		}
+		for _, r := range invalid {
+			if nil == r.Validate() {
+				t.Errorf("Must reject invalid retention: %+v", r)
+			}
+		}
+
+		var valid = []Retention{
+			{MaxAge: time.Second},
+			{MaxRows: 1},
+			{BelowCommitted: true},
+		}
+		for _, r := range valid {
+			e := r.Validate()
+			if nil != e {
+				t.Errorf("Unexpected error: %v", e)
+			}
+		}
+	})
+
+	t.Run("CompactSweepNew", func(t *testing.T) {
+		t.Parallel()
+
+		remain := int64(5)
+		var c Compact = func(_ context.Context, _ string, r Retention, limit int64) (int64, error) {
+			if 3 != r.MaxRows {
+				t.Errorf("Unexpected retention: %+v", r)
+			}
+			deleted := remain - r.MaxRows
+			if limit < deleted {
+				deleted = limit
+			}
+			remain -= deleted
+			return deleted, nil
+		}
+		total, e := SweepAll(context.Background(), CompactSweepNew(c, Retention{MaxRows: 3}), "l0", 1)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 2 != total || 3 != remain {
+			t.Errorf("Unexpected total/remain: %v/%v", total, remain)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package sql2keyval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type Scan func(ctx context.Context, bucket string, cb func(p Pair) error) error
+
+type ScanQueryGenerator interface {
+	Scan(bucket string) (query string, e error)
+}
+
+func record2pair(r Record) (p Pair, e error) {
+	e = r.Scan(&p.Key, &p.Val)
+	return
+}
+
+func scannerNew(g ScanQueryGenerator, q QueryCb) Scan {
+	return func(ctx context.Context, bucket string, cb func(p Pair) error) error {
+		query, e := g.Scan(bucket)
+		if nil != e {
+			return fmt.Errorf("Unable to get query for scan: %w", e)
+		}
+		return q(
+			ctx,
+			func(r Record) error {
+				p, e := record2pair(r)
+				if nil != e {
+					return fmt.Errorf("Unable to get key/val as byte arrays: %w", e)
+				}
+				return cb(p)
+			},
+			query,
+		)
+	}
+}
+
+// ScanIterNew creates an iterator which yields pairs got by the scan.
+// The scan runs on its own goroutine; cancel the context to stop it early.
+// The error(if any) can be checked(any number of times) by the returned function after the iterator ends.
+func ScanIterNew(ctx context.Context, s Scan, bucket string) (Iter[Pair], func() error) {
+	c := make(chan Pair)
+	done := make(chan error, 1)
+	go func() {
+		defer close(c)
+		done <- s(ctx, bucket, func(p Pair) error {
+			select {
+			case c <- p:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	var once sync.Once
+	var e error
+	return IterFromChan[Pair](c), func() error {
+		once.Do(func() { e = <-done })
+		return e
+	}
+}
+
+var scanFactory func(ScanQueryGenerator) func(QueryCb) Scan = curry(scannerNew)
+
+var ScanFactory func(driverName string) func(QueryCb) Scan = compose(
+	getQueryGeneratorExtOrEmpty[ScanQueryGenerator],
+	scanFactory,
+)
@@ -0,0 +1,86 @@
+package sql2keyval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestScanFactory(t *testing.T) {
+	t.Parallel()
+
+	var dummyFactory func(QueryCb) Scan = ScanFactory("does-not-exist")
+	var scanner Scan = dummyFactory(nil)
+	e := scanner(context.Background(), "", nil)
+	if nil == e {
+		t.Errorf("Must fail")
+	}
+}
+
+func TestScanIterNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pairs", func(t *testing.T) {
+		t.Parallel()
+		var dummyScan Scan = func(_ context.Context, _ string, cb func(p Pair) error) error {
+			for _, k := range []string{"a", "b", "c"} {
+				e := cb(Pair{Key: []byte(k), Val: []byte("v")})
+				if nil != e {
+					return e
+				}
+			}
+			return nil
+		}
+
+		i, ef := ScanIterNew(context.Background(), dummyScan, "b0")
+		if 3 != i.Count() {
+			t.Errorf("Unexpected number of pairs")
+		}
+		if nil != ef() {
+			t.Errorf("Unexpected error: %v", ef())
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		var dummyScan Scan = func(_ context.Context, _ string, _ func(p Pair) error) error {
+			return fmt.Errorf("Must fail")
+		}
+
+		i, ef := ScanIterNew(context.Background(), dummyScan, "b0")
+		if 0 != i.Count() {
+			t.Errorf("Must be empty")
+		}
+		e := ef()
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+		if e != ef() {
+			t.Errorf("Must return the same error")
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		var dummyScan Scan = func(_ context.Context, _ string, cb func(p Pair) error) error {
+			for {
+				e := cb(Pair{})
+				if nil != e {
+					return e
+				}
+			}
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		i, ef := ScanIterNew(ctx, dummyScan, "b0")
+		if 2 != i.Take(2).Count() {
+			t.Errorf("Unexpected number of pairs")
+		}
+		cancel()
+		for o := i(); o.HasValue(); o = i() {
+		}
+		if nil == ef() {
+			t.Errorf("Must be canceled")
+		}
+	})
+}
@@ -16,6 +16,9 @@ type AddBucket func(ctx context.Context, bucket string) error
 type AddLog func(ctx context.Context, bucket string) error
 type InsLog func(ctx context.Context, lg []byte) error
 
+// InsLogMany inserts logs in order and returns the assigned ids.
+type InsLogMany func(ctx context.Context, logs Iter[[]byte]) (ids []int64, e error)
+
 type Pair struct {
 	Key []byte
 	Val []byte
@@ -54,6 +54,38 @@ func (e *emptyQueryGenerator) Lst(_ string) (string, error)       { return "", e
 func (e *emptyQueryGenerator) DelBucket(_ string) (string, error) { return "", e.err }
 func (e *emptyQueryGenerator) AddBucket(_ string) (string, error) { return "", e.err }
 
+func (e *emptyQueryGenerator) LstRange(_ string, _ Range) (string, error) { return "", e.err }
+func (e *emptyQueryGenerator) Scan(_ string) (string, error)              { return "", e.err }
+func (e *emptyQueryGenerator) GetMany(_ string) (string, error)           { return "", e.err }
+
+func (e *emptyQueryGenerator) CasUpdate(_ string) (string, error)        { return "", e.err }
+func (e *emptyQueryGenerator) CasInsert(_ string) (string, error)        { return "", e.err }
+func (e *emptyQueryGenerator) GetVersion(_ string) (string, error)       { return "", e.err }
+func (e *emptyQueryGenerator) CasVersionUpdate(_ string) (string, error) { return "", e.err }
+func (e *emptyQueryGenerator) CasVersionInsert(_ string) (string, error) { return "", e.err }
+func (e *emptyQueryGenerator) AddVersionBucket(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) TtlGet(_ string) (string, error)       { return "", e.err }
+func (e *emptyQueryGenerator) TtlSet(_ string) (string, error)       { return "", e.err }
+func (e *emptyQueryGenerator) Sweep(_ string) (string, error)        { return "", e.err }
+func (e *emptyQueryGenerator) AddTtlBucket(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) Incr(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) LstBucket() (string, error)         { return "", e.err }
+func (e *emptyQueryGenerator) HasBucket(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) BucketStats(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) RenameBucket(_, _ string) (string, error) { return "", e.err }
+func (e *emptyQueryGenerator) CopyBucket(_, _ string) (string, error)   { return "", e.err }
+func (e *emptyQueryGenerator) TruncateBucket(_ string) (string, error)  { return "", e.err }
+
+func (e *emptyQueryGenerator) AddNamespace(_ string) (string, error)  { return "", e.err }
+func (e *emptyQueryGenerator) DropNamespace(_ string) (string, error) { return "", e.err }
+
+func (e *emptyQueryGenerator) AddPartitionedBucket(_ string, _ int) (string, error) { return "", e.err }
+
 func record2val(r Record) (v []byte, e error) {
 	e = r.Scan(&v)
 	return
@@ -74,14 +106,14 @@ func listNew(g QueryGenerator, q QueryCb) Lst {
 	return func(ctx context.Context, bucket string, cb func(key []byte) error) error {
 		query, e := g.Lst(bucket)
 		if nil != e {
-			return fmt.Errorf("Unable to get query for listing: %v", e)
+			return fmt.Errorf("Unable to get query for listing: %w", e)
 		}
 		return q(
 			ctx,
 			func(r Record) error {
 				v, e := record2val(r)
 				if nil != e {
-					return fmt.Errorf("Unable to get value as byte array: %v", e)
+					return fmt.Errorf("Unable to get value as byte array: %w", e)
 				}
 				return cb(v)
 			},
@@ -181,6 +213,16 @@ func getQueryGeneratorOrEmpty(driverName string) QueryGenerator {
 	return q
 }
 
+func getQueryGeneratorExtOrEmpty[T any](driverName string) T {
+	var q QueryGenerator = getQueryGeneratorOrEmpty(driverName)
+	t, ok := q.(T)
+	if ok {
+		return t
+	}
+	var empty any = &emptyQueryGenerator{err: fmt.Errorf("Unsupported query generator: %s", driverName)}
+	return empty.(T)
+}
+
 var GetFactory func(driverName string) func(Query) Get = compose(getQueryGeneratorOrEmpty, getFactory)
 var LstFactory func(driverName string) func(QueryCb) Lst = compose(getQueryGeneratorOrEmpty, lstFactory)
 var AddFactory func(driverName string) func(Exec) Add = compose(getQueryGeneratorOrEmpty, addFactory)
@@ -45,6 +45,7 @@ func TestRegisterQueryGenerator(t *testing.T) {
 		}
 
 		for _, p := range pat {
+			p := p
 			t.Run(p.n, func(t *testing.T) {
 				t.Parallel()
 				_, e := p.f("")
@@ -0,0 +1,35 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+type Stats struct {
+	Rows          int64   // exact row count
+	EstimatedRows int64   // estimated row count(negative: unknown, e.g, not yet analyzed)
+	TotalBytes    int64   // total size including indices(and partitions)
+	AvgValBytes   float64 // average size of values(0: empty bucket)
+}
+
+type BucketStats func(ctx context.Context, bucket string) (Stats, error)
+
+type StatsQueryGenerator interface {
+	// BucketStats: returns rows, estimated rows, total bytes, average value bytes
+	BucketStats(bucket string) (query string, e error)
+}
+
+func bucketStatsNew(g StatsQueryGenerator, q Query) BucketStats {
+	return func(ctx context.Context, bucket string) (s Stats, e error) {
+		query, e := g.BucketStats(bucket)
+		if nil != e {
+			return s, e
+		}
+		e = q(ctx, query).Scan(&s.Rows, &s.EstimatedRows, &s.TotalBytes, &s.AvgValBytes)
+		return
+	}
+}
+
+var BucketStatsFactory func(driverName string) func(Query) BucketStats = compose(
+	getQueryGeneratorExtOrEmpty[StatsQueryGenerator],
+	curry(bucketStatsNew),
+)
@@ -0,0 +1,45 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+type dummyStatsRecord struct{}
+
+func (d dummyStatsRecord) Scan(dest ...any) error {
+	*(dest[0].(*int64)) = 3
+	*(dest[1].(*int64)) = -1
+	*(dest[2].(*int64)) = 8192
+	*(dest[3].(*float64)) = 2.5
+	return nil
+}
+
+func TestBucketStatsFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("stats-dummy", &emptyQueryGenerator{})
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var s BucketStats = BucketStatsFactory("does-not-exist")(nil)
+		_, e := s(context.Background(), "b")
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		t.Parallel()
+		var q Query = func(_ context.Context, _ string, _ ...any) Record { return dummyStatsRecord{} }
+		var s BucketStats = BucketStatsFactory("stats-dummy")(q)
+		got, e := s(context.Background(), "b")
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		expected := Stats{Rows: 3, EstimatedRows: -1, TotalBytes: 8192, AvgValBytes: 2.5}
+		if expected != got {
+			t.Errorf("Unexpected stats: %v", got)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package sql2keyval
+
+import (
+	"context"
+)
+
+// Store bundles basic operations of a backend.
+type Store struct {
+	Get       Get
+	Set       Set
+	Add       Add
+	Del       Del
+	Lst       Lst
+	AddBucket AddBucket
+	DelBucket DelBucket
+}
+
+func StoreNew(driverName string, q Query, qc QueryCb, x Exec) Store {
+	var g QueryGenerator = getQueryGeneratorOrEmpty(driverName)
+	return Store{
+		Get:       getterNew(g, q),
+		Set:       setterNew(g, x),
+		Add:       adderNew(g, x),
+		Del:       removerNew(g, x),
+		Lst:       listNew(g, qc),
+		AddBucket: addBucketNew(g, x),
+		DelBucket: delBucketNew(g, x),
+	}
+}
+
+// WithTx runs f in a transaction: commit if f returns nil, rollback otherwise(including panic).
+type WithTx func(ctx context.Context, f func(tx Store) error) error
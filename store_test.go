@@ -0,0 +1,61 @@
+package sql2keyval
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		var s Store = StoreNew("does-not-exist", nil, nil, nil)
+		ctx := context.Background()
+
+		pat := []struct {
+			f func() error
+			n string
+		}{
+			{f: func() error { _, e := s.Get(ctx, "b", nil); return e }, n: "Get"},
+			{f: func() error { return s.Set(ctx, "b", nil, nil) }, n: "Set"},
+			{f: func() error { return s.Add(ctx, "b", nil, nil) }, n: "Add"},
+			{f: func() error { return s.Del(ctx, "b", nil) }, n: "Del"},
+			{f: func() error { return s.Lst(ctx, "b", nil) }, n: "Lst"},
+			{f: func() error { return s.AddBucket(ctx, "b") }, n: "AddBucket"},
+			{f: func() error { return s.DelBucket(ctx, "b") }, n: "DelBucket"},
+		}
+
+		for _, p := range pat {
+			p := p
+			t.Run(p.n, func(t *testing.T) {
+				t.Parallel()
+				if nil == p.f() {
+					t.Errorf("Must fail")
+				}
+			})
+		}
+	})
+
+	t.Run("dummy generator", func(t *testing.T) {
+		t.Parallel()
+
+		RegisterQueryGenerator("store-dummy", &emptyQueryGenerator{})
+
+		var executed []string
+		var x Exec = func(_ context.Context, query string, _ ...any) error {
+			executed = append(executed, query)
+			return nil
+		}
+
+		var s Store = StoreNew("store-dummy", nil, nil, x)
+		e := s.Set(context.Background(), "b", nil, nil)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 1 != len(executed) {
+			t.Errorf("Must be executed once")
+		}
+	})
+}
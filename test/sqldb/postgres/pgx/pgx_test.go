@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -280,6 +281,10 @@ func TestAll(t *testing.T) {
 			if nil == e {
 				t.Errorf("Must be rejected(dup key)")
 			}
+
+			if !errors.Is(e, sk.ErrDuplicateKey) {
+				t.Errorf("Must be ErrDuplicateKey: %v", e)
+			}
 		})
 
 		t.Run("del", func(t *testing.T) {
@@ -332,6 +337,10 @@ func TestAll(t *testing.T) {
 			if nil == e {
 				t.Errorf("Must be error")
 			}
+
+			if !errors.Is(e, sk.ErrNotFound) {
+				t.Errorf("Must be ErrNotFound: %v", e)
+			}
 		})
 
 		t.Run("add(after del)", func(t *testing.T) {
@@ -478,6 +487,99 @@ func TestAll(t *testing.T) {
 		})
 	})
 
+	t.Run("StoreNew", func(t *testing.T) {
+		t.Parallel() // sub tests: non parallel
+
+		var store sk.Store = ss.StoreNew("postgres")(testDb)
+		tablename := "teststore_cafef00d_dead_beaf_face_864299792458_ymd_2022_08_25"
+		key := []byte("14:18:07")
+
+		t.Run("add bucket", func(t *testing.T) {
+			e := store.AddBucket(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to create bucket: %v", e)
+			}
+		})
+
+		t.Run("set", func(t *testing.T) {
+			e := store.Set(context.Background(), tablename, key, []byte("hw"))
+			if nil != e {
+				t.Errorf("Unable to set key/value: %v", e)
+			}
+		})
+
+		t.Run("get", func(t *testing.T) {
+			got, e := store.Get(context.Background(), tablename, key)
+			if nil != e {
+				t.Errorf("Unable to get: %v", e)
+			}
+			if 0 != bytes.Compare(got, []byte("hw")) {
+				t.Errorf("Unexpected value got.")
+			}
+		})
+
+		t.Run("del bucket", func(t *testing.T) {
+			e := store.DelBucket(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to remove bucket: %v", e)
+			}
+		})
+	})
+
+	t.Run("Buckets", func(t *testing.T) {
+		t.Parallel() // sub tests: non parallel
+
+		var store sk.Store = ss.StoreNew("postgres")(testDb)
+		var lst sk.LstBucket = ss.LstBucketNew("postgres")(testDb)
+		var has sk.HasBucket = ss.HasBucketNew("postgres")(testDb)
+		tablename := "testbuckets_2022_08_25"
+
+		t.Run("add bucket", func(t *testing.T) {
+			e := store.AddBucket(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to create bucket: %v", e)
+			}
+		})
+
+		t.Run("has bucket", func(t *testing.T) {
+			found, e := has(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to check bucket: %v", e)
+			}
+			if !found {
+				t.Errorf("Bucket must exist")
+			}
+		})
+
+		t.Run("lst bucket", func(t *testing.T) {
+			found := false
+			e := lst(context.Background(), func(bucket string) error {
+				found = found || tablename == bucket
+				return nil
+			})
+			if nil != e {
+				t.Errorf("Unable to list buckets: %v", e)
+			}
+			if !found {
+				t.Errorf("Bucket must be listed")
+			}
+		})
+
+		t.Run("del bucket", func(t *testing.T) {
+			e := store.DelBucket(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to remove bucket: %v", e)
+			}
+			found, e := has(context.Background(), tablename)
+			if nil != e {
+				t.Errorf("Unable to check bucket: %v", e)
+			}
+			if found {
+				t.Errorf("Bucket must not exist")
+			}
+		})
+	})
+
 	t.Cleanup(func() {
 		testDb.Close()
 	})
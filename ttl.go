@@ -0,0 +1,128 @@
+package sql2keyval
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetWithTTL upserts key/val which expires after ttl(ttl <= 0: never expires).
+type SetWithTTL func(ctx context.Context, bucket string, key, val []byte, ttl time.Duration) error
+
+// Sweep deletes at most limit expired rows and returns the number of deleted rows.
+type Sweep func(ctx context.Context, bucket string, limit int64) (deleted int64, e error)
+
+type TtlQueryGenerator interface {
+	// TtlGet: $1 key; returns val if not expired
+	TtlGet(bucket string) (query string, e error)
+	// TtlSet: $1 key, $2 val, $3 ttl in microseconds
+	TtlSet(bucket string) (query string, e error)
+	// Sweep: $1 limit; returns number of deleted rows
+	Sweep(bucket string) (query string, e error)
+	AddTtlBucket(bucket string) (query string, e error)
+}
+
+func ttlGetNew(g TtlQueryGenerator, q Query) Get {
+	return func(ctx context.Context, bucket string, key []byte) (val []byte, e error) {
+		query, e := g.TtlGet(bucket)
+		if nil != e {
+			return nil, e
+		}
+		return record2val(q(ctx, query, key))
+	}
+}
+
+func setWithTtlNew(g TtlQueryGenerator, x Exec) SetWithTTL {
+	return func(ctx context.Context, bucket string, key, val []byte, ttl time.Duration) error {
+		query, e := g.TtlSet(bucket)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query, key, val, ttl.Microseconds())
+	}
+}
+
+func sweepNew(g TtlQueryGenerator, q Query) Sweep {
+	return func(ctx context.Context, bucket string, limit int64) (deleted int64, e error) {
+		query, e := g.Sweep(bucket)
+		if nil != e {
+			return 0, e
+		}
+		e = q(ctx, query, limit).Scan(&deleted)
+		return
+	}
+}
+
+func addTtlBucketNew(g TtlQueryGenerator, x Exec) AddBucket {
+	return func(ctx context.Context, bucket string) error {
+		query, e := g.AddTtlBucket(bucket)
+		if nil != e {
+			return e
+		}
+		return x(ctx, query)
+	}
+}
+
+var TtlGetFactory func(driverName string) func(Query) Get = compose(
+	getQueryGeneratorExtOrEmpty[TtlQueryGenerator],
+	curry(ttlGetNew),
+)
+
+var SetWithTTLFactory func(driverName string) func(Exec) SetWithTTL = compose(
+	getQueryGeneratorExtOrEmpty[TtlQueryGenerator],
+	curry(setWithTtlNew),
+)
+
+var SweepFactory func(driverName string) func(Query) Sweep = compose(
+	getQueryGeneratorExtOrEmpty[TtlQueryGenerator],
+	curry(sweepNew),
+)
+
+var AddTtlBucketFactory func(driverName string) func(Exec) AddBucket = compose(
+	getQueryGeneratorExtOrEmpty[TtlQueryGenerator],
+	curry(addTtlBucketNew),
+)
+
+// SweepAll deletes expired rows in batches of limit until no full batch remains.
+func SweepAll(ctx context.Context, s Sweep, bucket string, limit int64) (total int64, e error) {
+	if limit < 1 {
+		return 0, fmt.Errorf("Invalid limit: %v", limit)
+	}
+	for {
+		deleted, e := s(ctx, bucket, limit)
+		total += deleted
+		if nil != e {
+			return total, e
+		}
+		if deleted < limit {
+			return total, nil
+		}
+		e = ctx.Err()
+		if nil != e {
+			return total, e
+		}
+	}
+}
+
+// SweeperStart starts a goroutine which sweeps the bucket every interval until ctx is done.
+// Sweep errors are passed to onErr(nil: ignored); the returned channel is closed after the goroutine exits.
+func SweeperStart(ctx context.Context, s Sweep, bucket string, interval time.Duration, limit int64, onErr func(error)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, e := SweepAll(ctx, s, bucket, limit)
+				if nil != e && nil != onErr && nil == ctx.Err() {
+					onErr(e)
+				}
+			}
+		}
+	}()
+	return done
+}
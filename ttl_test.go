@@ -0,0 +1,113 @@
+package sql2keyval
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetWithTTLFactory(t *testing.T) {
+	t.Parallel()
+
+	RegisterQueryGenerator("ttl-dummy", &emptyQueryGenerator{})
+
+	t.Run("does not exist", func(t *testing.T) {
+		t.Parallel()
+		var s SetWithTTL = SetWithTTLFactory("does-not-exist")(nil)
+		e := s(context.Background(), "b", nil, nil, time.Second)
+		if nil == e {
+			t.Errorf("Must fail")
+		}
+	})
+
+	t.Run("ttl in microseconds", func(t *testing.T) {
+		t.Parallel()
+		var got any
+		var x Exec = func(_ context.Context, _ string, args ...any) error {
+			got = args[2]
+			return nil
+		}
+		var s SetWithTTL = SetWithTTLFactory("ttl-dummy")(x)
+		e := s(context.Background(), "b", []byte("k"), []byte("v"), time.Second)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if int64(1000000) != got {
+			t.Errorf("Unexpected ttl: %v", got)
+		}
+	})
+}
+
+func TestSweepAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid limit", func(t *testing.T) {
+		t.Parallel()
+		_, e := SweepAll(context.Background(), nil, "b", 0)
+		if nil == e {
+			t.Errorf("Must reject invalid limit")
+		}
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		t.Parallel()
+		remain := int64(25)
+		calls := 0
+		var s Sweep = func(_ context.Context, _ string, limit int64) (int64, error) {
+			calls += 1
+			deleted := limit
+			if remain < limit {
+				deleted = remain
+			}
+			remain -= deleted
+			return deleted, nil
+		}
+		total, e := SweepAll(context.Background(), s, "b", 10)
+		if nil != e {
+			t.Errorf("Unexpected error: %v", e)
+		}
+		if 25 != total {
+			t.Errorf("Unexpected total: %v", total)
+		}
+		if 3 != calls {
+			t.Errorf("Unexpected number of calls: %v", calls)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		var s Sweep = func(_ context.Context, _ string, _ int64) (int64, error) {
+			return 0, ErrBucketNotFound
+		}
+		_, e := SweepAll(context.Background(), s, "b", 10)
+		if !errors.Is(e, ErrBucketNotFound) {
+			t.Errorf("Unexpected error: %v", e)
+		}
+	})
+}
+
+func TestSweeperStart(t *testing.T) {
+	t.Parallel()
+
+	var calls int64
+	var s Sweep = func(_ context.Context, _ string, _ int64) (int64, error) {
+		atomic.AddInt64(&calls, 1)
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := SweeperStart(ctx, s, "b", time.Millisecond, 10, nil)
+
+	for 0 == atomic.LoadInt64(&calls) {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Sweeper must stop after cancel")
+	}
+}